@@ -0,0 +1,69 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidColumn returned when aggregating a column which isn't an identifier
+var ErrInvalidColumn = errors.New("query: invalid column")
+
+// SumInt sum the integer column over the filtered rows, 0 without rows
+func (b *Builder) SumInt(ctx context.Context, column string) (int64, error) {
+	var sum int64
+	var err = b.aggregate(ctx, "COALESCE(SUM(%s), 0)", column, &sum)
+	return sum, err
+}
+
+// Sum sum the column over the filtered rows, 0 without rows
+func (b *Builder) Sum(ctx context.Context, column string) (float64, error) {
+	var sum float64
+	var err = b.aggregate(ctx, "COALESCE(SUM(%s), 0)", column, &sum)
+	return sum, err
+}
+
+// Avg average of the column over the filtered rows, 0 without rows
+func (b *Builder) Avg(ctx context.Context, column string) (float64, error) {
+	var avg sql.NullFloat64
+	var err = b.aggregate(ctx, "AVG(%s)", column, &avg)
+	return avg.Float64, err
+}
+
+// Min scan the smallest value of the column into dest, which should be nullable, e.g. *sql.NullTime
+func (b *Builder) Min(ctx context.Context, column string, dest interface{}) error {
+	return b.aggregate(ctx, "MIN(%s)", column, dest)
+}
+
+// Max scan the largest value of the column over the filtered rows into dest, see Min
+func (b *Builder) Max(ctx context.Context, column string, dest interface{}) error {
+	return b.aggregate(ctx, "MAX(%s)", column, dest)
+}
+
+// aggregate run the aggregate of the column over the conditions, like the count query
+func (b *Builder) aggregate(ctx context.Context, format string, column string, dest interface{}) error {
+	if !isIdentifier(column) {
+		return fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+	}
+
+	with, _ := b.buildWith()
+	var stmt = statement{
+		sql:    b.hintComment() + wrapQuery(with, b.buildBody(), fmt.Sprintf(format, column)),
+		values: b.countValues(),
+	}
+
+	if err := b.beforeExec(ctx, stmt); err != nil {
+		return err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return b.session(ctx, func(exec Executor, dedicated bool) error {
+		return exec.QueryRowContext(ctx, stmt.sql, stmt.values...).Scan(dest)
+	})
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type arrayValue struct {
+	slice interface{}
+}
+
+// Array bind a slice as a single postgres array parameter, instead of gorm expanding it into (?, ?, ...)
+func Array(slice interface{}) driver.Valuer {
+	return arrayValue{slice: slice}
+}
+
+// Value encode the slice as an array literal, e.g. {"a","b\"c",NULL}
+func (a arrayValue) Value() (driver.Value, error) {
+	var rv = reflect.ValueOf(a.slice)
+	if a.slice == nil || (rv.Kind() == reflect.Slice && rv.IsNil()) {
+		return "{}", nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("query: array value must be a slice, got %T", a.slice)
+	}
+
+	var elems = make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		var elem = rv.Index(i)
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				break
+			}
+			elem = elem.Elem()
+		}
+
+		switch {
+		case (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && elem.IsNil():
+			elems = append(elems, "NULL")
+		case elem.Kind() == reflect.String:
+			elems = append(elems, quoteArrayElem(elem.String()))
+		default:
+			elems = append(elems, quoteArrayElem(fmt.Sprint(elem.Interface())))
+		}
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(elems, ",")), nil
+}
+
+func quoteArrayElem(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return fmt.Sprintf(`"%s"`, s)
+}
+
+// WhereAnyOf filter rows whose column equals any of values (column = ANY(?)), bound as one array parameter
+func (b *Builder) WhereAnyOf(column string, values interface{}) *Builder {
+	return b.Where(fmt.Sprintf("%s = ANY(?)", column), Array(values))
+}
+
+// WhereArrayOverlaps filter rows whose array column shares at least one element with values (&&)
+func (b *Builder) WhereArrayOverlaps(column string, values interface{}) *Builder {
+	return b.Where(fmt.Sprintf("%s && ?", column), Array(values))
+}
+
+// WhereArrayContains filter rows whose array column contains all of values (@>)
+func (b *Builder) WhereArrayContains(column string, values interface{}) *Builder {
+	return b.Where(fmt.Sprintf("%s @> ?", column), Array(values))
+}
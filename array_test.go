@@ -0,0 +1,32 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestArrayValue(t *testing.T) {
+	var name = "x"
+	var tests = []struct {
+		in   interface{}
+		want string
+	}{
+		{in: []string{"go", `say "hi"`, `a\b`}, want: `{"go","say \"hi\"","a\\b"}`},
+		{in: []int{1, 2, 3}, want: `{"1","2","3"}`},
+		{in: []*string{&name, nil}, want: `{"x",NULL}`},
+		{in: []string(nil), want: `{}`},
+	}
+
+	for _, test := range tests {
+		got, err := Array(test.in).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("got %v, want %v", got, test.want)
+		}
+	}
+
+	if _, err := Array("tags").Value(); err == nil {
+		t.Errorf("expected an error for non slice values")
+	}
+}
@@ -0,0 +1,79 @@
+package query_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	query "github.com/thaitanloi365/go-query"
+	"github.com/thaitanloi365/go-query/querytest"
+)
+
+// Allocation targets of a paginated request with four conditions: building the SQL and values,
+// and the whole PagingRows on the fake executor, database/sql included.
+const (
+	maxBuildAllocs  = 20
+	maxPagingAllocs = 50
+)
+
+func benchmarkBuilder(exec query.Executor) *query.Builder {
+	return query.NewWithExecutor(exec, "SELECT u.id, u.name FROM users u").
+		Joins("JOIN roles r ON r.id = u.role_id").
+		Where("u.status = ?", "active").
+		Where("u.age > ?", 18).
+		Where("r.name IN ?", []string{"admin", "owner"}).
+		Where("u.created_at > ?", "2021-01-01").
+		OrderBy("u.id DESC").
+		Limit(20).
+		Page(3)
+}
+
+func BenchmarkBuild(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkBuilder(nil).ToSQL()
+	}
+}
+
+func BenchmarkPagingRows(b *testing.B) {
+	var exec = querytest.New(query.DialectPostgres).
+		Rows("", []string{"id", "name"}, []interface{}{1, "a"}).
+		Count(100)
+	var ctx = context.Background()
+	var discard = func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		exec.Reset()
+		if _, err := benchmarkBuilder(exec).PagingRows(ctx, discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	var exec = querytest.New(query.DialectPostgres).
+		Rows("", []string{"id", "name"}, []interface{}{1, "a"}).
+		Count(100)
+	var ctx = context.Background()
+	var discard = func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	}
+
+	var allocs = testing.AllocsPerRun(100, func() {
+		benchmarkBuilder(nil).ToSQL()
+	})
+	if allocs > maxBuildAllocs {
+		t.Fatalf("build: %v allocs, target %d", allocs, maxBuildAllocs)
+	}
+
+	allocs = testing.AllocsPerRun(100, func() {
+		exec.Reset()
+		benchmarkBuilder(exec).PagingRows(ctx, discard)
+	})
+	if allocs > maxPagingAllocs {
+		t.Fatalf("paging: %v allocs, target %d", allocs, maxPagingAllocs)
+	}
+}
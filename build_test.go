@@ -0,0 +1,50 @@
+package query
+
+import "testing"
+
+func TestBuildWhereChain(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users u").
+		Joins("JOIN roles r ON r.id = u.role_id").
+		Where("u.name = @name").
+		Where("u.id IN (@id, @id2)").
+		Where("r.level > ?", 2).
+		WhereNamed("name", "john").
+		WhereNamed("id", 1).
+		WhereNamed("id2", 2).
+		GroupBy("u.id").
+		OrderBy("u.id").
+		Limit(10).
+		Page(3)
+
+	queryString, countQuery := b.build()
+
+	var body = "SELECT * FROM users u JOIN roles r ON r.id = u.role_id WHERE u.name = 'john' AND u.id IN (1, 2) AND r.level > ? GROUP BY u.id"
+	if queryString != body+" ORDER BY u.id LIMIT 10 OFFSET 20" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if countQuery != "SELECT COUNT(1) FROM ("+body+") t" {
+		t.Fatalf("unexpected count query: %s", countQuery)
+	}
+}
+
+func TestNewE(t *testing.T) {
+	var db *DBTest
+
+	if _, err := NewE(nil, "SELECT 1"); err != ErrNilDB {
+		t.Fatalf("expected ErrNilDB, got %v", err)
+	}
+	if _, err := NewE(db, "SELECT 1"); err != ErrNilDB {
+		t.Fatalf("expected ErrNilDB for typed nil, got %v", err)
+	}
+
+	db = &DBTest{}
+	if _, err := NewE(db, " \n"); err != ErrEmptySQL {
+		t.Fatalf("expected ErrEmptySQL, got %v", err)
+	}
+	if _, err := NewE(db, "SELECT * FROM users LIMIT 10"); err != ErrLimitInSQL {
+		t.Fatalf("expected ErrLimitInSQL, got %v", err)
+	}
+	if _, err := NewE(db, "SELECT * FROM users WHERE id IN (SELECT id FROM admins LIMIT 10)"); err != nil {
+		t.Fatalf("subquery limit should be allowed: %v", err)
+	}
+}
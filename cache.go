@@ -0,0 +1,297 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores cached results, in memory with MemoryCache or e.g. in Redis
+type CacheStore interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type resultCache struct {
+	ttl   time.Duration
+	tags  []string
+	store CacheStore
+	codec CacheCodec
+}
+
+// cacheEntry gob envelope of a cached page, records are encoded by the codec, metadata and summary as JSON
+type cacheEntry struct {
+	Codec        string
+	Type         string
+	Records      []byte
+	Total        int
+	MetadataType string
+	Metadata     []byte
+	Summary      []byte
+	Window       *TimeWindow
+}
+
+var cacheStore CacheStore = NewMemoryCache(10000)
+
+// SetCacheStore replace the default in-memory store of cached builders
+func SetCacheStore(store CacheStore) {
+	cacheStore = store
+}
+
+// Cached cache the pages for ttl per pool, schema and query under the tags, see InvalidateCache
+func (b *Builder) Cached(ttl time.Duration, tags ...string) *Builder {
+	b.cache = &resultCache{
+		ttl:   ttl,
+		tags:  tags,
+		store: cacheStore,
+		codec: cacheCodec,
+	}
+	return b
+}
+
+// WithCacheStore cache in store instead of the default store, call after Cached
+func (b *Builder) WithCacheStore(store CacheStore) *Builder {
+	if b.cache != nil {
+		b.cache.store = store
+	}
+	return b
+}
+
+// InvalidateCache drop the pages cached under the tags from the default store
+func InvalidateCache(ctx context.Context, tags ...string) error {
+	return invalidateCache(ctx, cacheStore, tags...)
+}
+
+// CacheInvalidator invalidator dropping the pages cached under the tags, e.g. to subscribe a Listener
+func CacheInvalidator(tags ...string) Invalidator {
+	return InvalidatorFunc(func(channel string, payload string) {
+		InvalidateCache(context.Background(), tags...)
+	})
+}
+
+// invalidateCache bump the generation of the tags, older keys are never read again and expire
+func invalidateCache(ctx context.Context, store CacheStore, tags ...string) error {
+	var generation = []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+	for _, tag := range tags {
+		if err := store.Set(ctx, "query:tag:"+tag, generation, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key of the page, including the current generation of the tags
+func (c *resultCache) key(ctx context.Context, b *Builder, stmt statement) (string, error) {
+	var hash = sha256.New()
+	for _, tag := range c.tags {
+		generation, _, err := c.store.Get(ctx, "query:tag:"+tag)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s=%s;", tag, generation)
+	}
+	scope, err := b.scope(ctx)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(hash, "%s;%s;%#v;%d;%d", scope, stmt.sql, stmt.values, b.limit, b.page)
+
+	return "query:page:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// scope pool and schema of the builder, the pool is identified within the process only
+func (b *Builder) scope(ctx context.Context) (string, error) {
+	schema, err := b.resolveSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var pool = b.handle()
+	if v := reflect.ValueOf(pool); v.Kind() == reflect.Ptr {
+		return fmt.Sprintf("%T@%x;%s", pool, v.Pointer(), schema), nil
+	}
+	return fmt.Sprintf("%T;%s", pool, schema), nil
+}
+
+// cachedPaging serve the page from the cache, or run paging and cache its result
+func (b *Builder) cachedPaging(ctx context.Context, stmt statement, paging func() (*Pagination, error)) (*Pagination, error) {
+	key, err := b.cache.key(ctx, b, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok, err := b.cache.store.Get(ctx, key); err == nil && ok {
+		if pagination, ok := b.decodePage(data); ok {
+			return pagination, nil
+		}
+	}
+
+	if b.degraded > 0 && !isHealthy(b.handle()) {
+		if pagination, ok := b.stalePage(ctx, stmt); ok {
+			return pagination, nil
+		}
+	}
+
+	pagination, err := paging()
+	if err != nil {
+		// database failures, not invalid builders, fall back to the stale page
+		if b.degraded > 0 && b.validate() == nil {
+			if stale, ok := b.stalePage(ctx, stmt); ok {
+				return stale, nil
+			}
+		}
+		return nil, err
+	}
+
+	total, err := pagination.Total(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry = cacheEntry{
+		Codec:  b.cache.codec.Name(),
+		Total:  total,
+		Window: pagination.Window,
+	}
+	var ok bool
+	if entry.Type, entry.Records, ok, err = encodeRecords(b.cache.codec, pagination.Records); err != nil || !ok {
+		return pagination, err
+	}
+	if pagination.Metadata != nil {
+		if entry.MetadataType, entry.Metadata, _, err = encodeRecords(JSONCodec, pagination.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if pagination.Summary != nil {
+		if entry.Summary, err = json.Marshal(pagination.Summary); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return nil, err
+	}
+
+	// a failing cache doesn't fail the request
+	b.cache.store.Set(ctx, key, buf.Bytes(), b.cache.ttl)
+	if b.degraded > 0 {
+		if key, err := staleKey(ctx, b, stmt); err == nil {
+			b.cache.store.Set(ctx, key, buf.Bytes(), b.degraded)
+		}
+	}
+	return pagination, nil
+}
+
+// decodePage decode a cached page, false when it was cached by another codec or type
+func (b *Builder) decodePage(data []byte) (*Pagination, bool) {
+	var entry cacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil || entry.Codec != b.cache.codec.Name() {
+		return nil, false
+	}
+
+	records, ok := decodeRecords(b.cache.codec, entry.Type, entry.Records)
+	if !ok {
+		return nil, false
+	}
+
+	var pagination = b.paginate(entry.Total, records)
+	pagination.Window = entry.Window
+	if len(entry.Metadata) > 0 {
+		pagination.Metadata, _ = decodeRecords(JSONCodec, entry.MetadataType, entry.Metadata)
+	}
+	if len(entry.Summary) > 0 && json.Unmarshal(entry.Summary, &pagination.Summary) != nil {
+		return nil, false
+	}
+	return pagination, true
+}
+
+// MemoryCache in-process CacheStore
+type MemoryCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache init a memory store keeping at most maxEntries, expired entries are dropped first
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]memoryEntry{},
+	}
+}
+
+// Get ...
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+// Set ttl 0 keeps the value until evicted
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+
+	var entry = memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+// evict drop the expired entries, or an arbitrary one when none expired
+func (c *MemoryCache) evict() {
+	var now = time.Now()
+	var evicted = false
+	for key, entry := range c.entries {
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			evicted = true
+		}
+	}
+	if evicted {
+		return
+	}
+
+	// tag generations go last, dropping one would serve the pages cached before its invalidation
+	for key := range c.entries {
+		if !strings.HasPrefix(key, "query:tag:") {
+			delete(c.entries, key)
+			return
+		}
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
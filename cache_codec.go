@@ -0,0 +1,107 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// CacheCodec encode the cached records, msgpack libraries only need a Name
+type CacheCodec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string {
+	return "gob"
+}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	var err = gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+var (
+	// JSONCodec default codec, unregistered records are served back as json.RawMessage
+	JSONCodec CacheCodec = jsonCodec{}
+	// GobCodec binary codec, only records of registered types are cached
+	GobCodec CacheCodec = gobCodec{}
+)
+
+var cacheCodec = JSONCodec
+
+// registered record types by name
+var cacheTypes sync.Map
+
+// SetCacheCodec replace the default codec of cached builders
+func SetCacheCodec(codec CacheCodec) {
+	cacheCodec = codec
+}
+
+// RegisterCacheType register the type of records, e.g. []*User{}, so cache hits return them typed
+func RegisterCacheType(records interface{}) {
+	var t = reflect.TypeOf(records)
+	cacheTypes.Store(t.String(), t)
+}
+
+// WithCacheCodec encode the cached records with codec, call after Cached
+func (b *Builder) WithCacheCodec(codec CacheCodec) *Builder {
+	if b.cache != nil {
+		b.cache.codec = codec
+	}
+	return b
+}
+
+// encodeRecords encode records with the codec, ok false when the codec can't decode them back
+func encodeRecords(codec CacheCodec, records interface{}) (typeName string, data []byte, ok bool, err error) {
+	if records != nil {
+		typeName = reflect.TypeOf(records).String()
+	}
+	if _, registered := cacheTypes.Load(typeName); !registered && codec.Name() != JSONCodec.Name() {
+		return "", nil, false, nil
+	}
+
+	data, err = codec.Marshal(records)
+	return typeName, data, err == nil, err
+}
+
+// decodeRecords decode records of a registered type, or as json.RawMessage with the JSON codec
+func decodeRecords(codec CacheCodec, typeName string, data []byte) (interface{}, bool) {
+	t, registered := cacheTypes.Load(typeName)
+	if !registered {
+		if codec.Name() != JSONCodec.Name() {
+			return nil, false
+		}
+		return json.RawMessage(data), true
+	}
+
+	var records = reflect.New(t.(reflect.Type))
+	if err := codec.Unmarshal(data, records.Interface()); err != nil {
+		return nil, false
+	}
+	return records.Elem().Interface(), true
+}
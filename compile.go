@@ -0,0 +1,110 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrArgCount returned when a compiled query runs with a wrong number of values
+var ErrArgCount = errors.New("query: wrong number of values for compiled query")
+
+// CompiledQuery immutable query built once by Compile, safe for concurrent use
+type CompiledQuery struct {
+	builder  Builder
+	sql      string
+	countSQL string
+	values   []interface{}
+	countN   int
+}
+
+// Compile freeze the SQL, LIMIT and OFFSET are bound as parameters
+func (b *Builder) Compile() (*CompiledQuery, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	var frozen = *b
+	frozen.prepared = true
+	if frozen.page < 1 {
+		frozen.page = 1
+	}
+
+	sqlString, countSQLString := frozen.build()
+	var values = frozen.appendCountValues(nil)
+	var countN = len(values)
+	values = append(values, frozen.orderValues...)
+	values = append(values, frozen.sortValues...)
+	unwrapUserInput(values)
+
+	return &CompiledQuery{
+		builder:  frozen,
+		sql:      sqlString,
+		countSQL: countSQLString,
+		values:   values,
+		countN:   countN,
+	}, nil
+}
+
+// Page copy of the query on page
+func (cq *CompiledQuery) Page(page int) *CompiledQuery {
+	var c = *cq
+	if page < 1 {
+		page = 1
+	}
+	c.builder.page = page
+	return &c
+}
+
+// SQL compiled data query, with ? placeholders
+func (cq *CompiledQuery) SQL() string {
+	return cq.sql
+}
+
+// Exec run the data query of the page. Args replace the compiled values in order, none keeps them.
+func (cq *CompiledQuery) Exec(ctx context.Context, args ...interface{}) (*sql.Rows, error) {
+	values, _, err := cq.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return cq.builder.queryRows(ctx, statement{cq.sql, values})
+}
+
+// Count run the count query, args as for Exec
+func (cq *CompiledQuery) Count(ctx context.Context, args ...interface{}) (int, error) {
+	_, countValues, err := cq.bind(args)
+	if err != nil {
+		return 0, err
+	}
+
+	return cq.builder.queryCount(ctx, statement{cq.countSQL, countValues})
+}
+
+// Paging run the data and count queries of the page, args as for Exec
+func (cq *CompiledQuery) Paging(ctx context.Context, f RowsFunc, args ...interface{}) (*Pagination, error) {
+	values, countValues, err := cq.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return cq.builder.pagingRows(ctx, statement{cq.sql, values}, statement{cq.countSQL, countValues}, f)
+}
+
+// bind values of the data and count queries for args and the current page
+func (cq *CompiledQuery) bind(args []interface{}) (values []interface{}, countValues []interface{}, err error) {
+	if len(args) == 0 {
+		args = cq.values
+	} else if len(args) != len(cq.values) {
+		return nil, nil, ErrArgCount
+	}
+
+	values = make([]interface{}, 0, len(args)+2)
+	values = append(values, args...)
+	if cq.builder.limit > 0 {
+		values = append(values, cq.builder.limit)
+	}
+	values = append(values, cq.builder.offset())
+
+	return values, args[:cq.countN], nil
+}
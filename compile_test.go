@@ -0,0 +1,43 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users").
+		Where("status = @status").
+		Where("age > ?", 18).
+		WhereNamed("status", "active").
+		OrderBy("id").
+		Limit(20)
+
+	cq, err := b.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes after Compile are not picked up
+	b.Where("deleted_at IS NULL")
+
+	if cq.SQL() != "SELECT * FROM users WHERE status = 'active' AND age > ? ORDER BY id LIMIT ? OFFSET ?" {
+		t.Fatalf("unexpected query: %s", cq.SQL())
+	}
+
+	values, countValues, err := cq.Page(3).bind([]interface{}{30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []interface{}{30, 20, 40}) || !reflect.DeepEqual(countValues, []interface{}{30}) {
+		t.Fatalf("unexpected values: %v %v", values, countValues)
+	}
+
+	if values, _, _ = cq.bind(nil); !reflect.DeepEqual(values, []interface{}{18, 20, 0}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	if _, _, err = cq.bind([]interface{}{1, 2}); err != ErrArgCount {
+		t.Fatalf("expected ErrArgCount, got %v", err)
+	}
+}
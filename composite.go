@@ -0,0 +1,152 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Composite postgres composite column in text form, NULL fields are invalid NullStrings
+type Composite []sql.NullString
+
+// Scan ...
+func (c *Composite) Scan(src interface{}) error {
+	var source string
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		source = v
+	case []byte:
+		source = string(v)
+	default:
+		return errors.New("Incompatible type for Composite")
+	}
+
+	if len(source) < 2 || source[0] != '(' || source[len(source)-1] != ')' {
+		return fmt.Errorf("query: invalid composite %q", source)
+	}
+
+	var fields = Composite{}
+	var body = source[1 : len(source)-1]
+	var field strings.Builder
+	var quoted, inQuotes bool
+	for i := 0; i < len(body); i++ {
+		var ch = body[i]
+		switch {
+		case inQuotes && ch == '\\' && i+1 < len(body):
+			i++
+			field.WriteByte(body[i])
+		case inQuotes && ch == '"' && i+1 < len(body) && body[i+1] == '"':
+			i++
+			field.WriteByte('"')
+		case ch == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case !inQuotes && ch == ',':
+			fields = append(fields, sql.NullString{String: field.String(), Valid: quoted || field.Len() > 0})
+			field.Reset()
+			quoted = false
+		default:
+			field.WriteByte(ch)
+		}
+	}
+	if inQuotes {
+		return fmt.Errorf("query: unterminated composite %q", source)
+	}
+	fields = append(fields, sql.NullString{String: field.String(), Valid: quoted || field.Len() > 0})
+
+	*c = fields
+	return nil
+}
+
+// Unmarshal assign the fields in order to the exported fields of the dest struct
+func (c Composite) Unmarshal(dest interface{}) error {
+	var rv = reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query: composite dest must be a pointer to struct, got %T", dest)
+	}
+	rv = rv.Elem()
+
+	var index = 0
+	for i := 0; i < rv.NumField() && index < len(c); i++ {
+		if rv.Type().Field(i).PkgPath != "" {
+			continue
+		}
+
+		if err := setCompositeField(rv.Field(i), c[index]); err != nil {
+			return fmt.Errorf("query: composite field %s: %w", rv.Type().Field(i).Name, err)
+		}
+		index++
+	}
+
+	return nil
+}
+
+func setCompositeField(field reflect.Value, value sql.NullString) error {
+	if field.Kind() == reflect.Ptr {
+		if !value.Valid {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		var elem = reflect.New(field.Type().Elem())
+		if err := setCompositeField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if !value.Valid {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value.String)
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999-07", "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, value.String); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", value.String)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value.String)
+	case reflect.Bool:
+		field.SetBool(value.String == "t" || value.String == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value.String, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value.String, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value.String, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported kind %s", field.Kind())
+	}
+
+	return nil
+}
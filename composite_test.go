@@ -0,0 +1,44 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestHstoreScan(t *testing.T) {
+	var h Hstore
+	if err := h.Scan(`"plan"=>"pro", "note"=>"say \"hi\"", "trial"=>NULL`); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 || h["plan"] != "pro" || h["note"] != `say "hi"` || h["trial"] != "" {
+		t.Fatalf("unexpected hstore: %v", h)
+	}
+
+	value, _ := h.Value()
+	if value != `"note"=>"say \"hi\"", "plan"=>"pro", "trial"=>""` {
+		t.Fatalf("unexpected value: %v", value)
+	}
+}
+
+func TestCompositeUnmarshal(t *testing.T) {
+	var c Composite
+	if err := c.Scan(`(12,"Le Loi, District 1",,t,"say ""hi""")`); err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 5 || c[2].Valid || c[4].String != `say "hi"` {
+		t.Fatalf("unexpected composite: %v", c)
+	}
+
+	var address struct {
+		ID      int
+		Street  string
+		Zip     *string
+		Primary bool
+		Note    string
+	}
+	if err := c.Unmarshal(&address); err != nil {
+		t.Fatal(err)
+	}
+	if address.ID != 12 || address.Street != "Le Loi, District 1" || address.Zip != nil || !address.Primary {
+		t.Fatalf("unexpected address: %+v", address)
+	}
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"gorm.io/gorm"
+)
+
+// ErrCopyNotSupported returned when the db handle is not backed by the pgx driver
+var ErrCopyNotSupported = errors.New("query: copy requires the pgx postgres driver")
+
+// CopyFrom bulk load rows into table with COPY on a dedicated pgx connection
+func CopyFrom(ctx context.Context, db *gorm.DB, table string, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int64
+	err = conn.Raw(func(driverConn interface{}) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return ErrCopyNotSupported
+		}
+
+		count, err = stdlibConn.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, rows)
+		return err
+	})
+
+	return count, err
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrQueryTooExpensive returned when the planner estimate exceeds the limits set by WithMaxCost/WithMaxRows
+var ErrQueryTooExpensive = errors.New("query: query too expensive")
+
+// WithMaxCost refuse to run queries whose planner total cost exceeds cost
+func (b *Builder) WithMaxCost(cost float64) *Builder {
+	b.maxCost = cost
+	return b
+}
+
+// WithMaxRows refuse to run queries whose planner estimated rows exceed rows
+func (b *Builder) WithMaxRows(rows float64) *Builder {
+	b.maxRows = rows
+	return b
+}
+
+// checkCost explain the statements and compare the estimates against the limits
+func (b *Builder) checkCost(ctx context.Context, statements ...statement) error {
+	if b.maxCost <= 0 && b.maxRows <= 0 {
+		return nil
+	}
+
+	// explained in the session, so tenant queries are planned against their schema
+	return b.session(ctx, func(exec Executor, dedicated bool) error {
+		for _, stmt := range statements {
+			plan, err := b.explain(ctx, exec, stmt, false)
+			if errors.Is(err, ErrExplainNotSupported) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if b.maxCost > 0 && plan.TotalCost > b.maxCost {
+				return fmt.Errorf("%w: cost %.0f exceeds %.0f", ErrQueryTooExpensive, plan.TotalCost, b.maxCost)
+			}
+
+			if b.maxRows > 0 && plan.EstimatedRows > b.maxRows {
+				return fmt.Errorf("%w: estimated rows %.0f exceeds %.0f", ErrQueryTooExpensive, plan.EstimatedRows, b.maxRows)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+type cte struct {
+	name      string
+	recursive bool
+	base      *Builder
+	step      *Builder
+	expr      *clause.Expr
+}
+
+// With add a common table expression named name, built from builder
+func (b *Builder) With(name string, builder *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{
+		name: name,
+		base: builder,
+	})
+	return b
+}
+
+// WithValues add a common table expression selecting from a VALUES list, see Values
+func (b *Builder) WithValues(name string, values clause.Expr) *Builder {
+	b.ctes = append(b.ctes, cte{
+		name: name,
+		expr: &values,
+	})
+	return b
+}
+
+// WithRecursive add a recursive CTE, name can carry columns, e.g. "tree(id, parent_id)"
+func (b *Builder) WithRecursive(name string, base *Builder, recursive *Builder) *Builder {
+	b.ctes = append(b.ctes, cte{
+		name:      name,
+		recursive: true,
+		base:      base,
+		step:      recursive,
+	})
+	return b
+}
+
+// appendWithValues append the bound values of the WITH clause to values
+func (b *Builder) appendWithValues(values []interface{}) []interface{} {
+	for _, c := range b.ctes {
+		if c.expr != nil {
+			values = append(values, c.expr.Vars...)
+			continue
+		}
+
+		values = append(values, c.base.values()...)
+		if c.recursive {
+			values = append(values, c.step.values()...)
+		}
+	}
+
+	return values
+}
+
+// buildWith build the WITH clause and its bound values
+func (b *Builder) buildWith() (clause string, values []interface{}) {
+	if len(b.ctes) == 0 {
+		return
+	}
+
+	var keyword = "WITH"
+	var parts = []string{}
+	for _, c := range b.ctes {
+		if c.expr != nil {
+			values = append(values, c.expr.Vars...)
+			parts = append(parts, fmt.Sprintf("%s AS (SELECT * FROM %s)", c.name, c.expr.SQL))
+			continue
+		}
+
+		baseSQL, _ := c.base.build()
+		values = append(values, c.base.values()...)
+
+		if c.recursive {
+			keyword = "WITH RECURSIVE"
+			stepSQL, _ := c.step.build()
+			values = append(values, c.step.values()...)
+			parts = append(parts, fmt.Sprintf("%s AS (%s UNION ALL %s)", c.name, baseSQL, stepSQL))
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%s AS (%s)", c.name, baseSQL))
+	}
+
+	clause = fmt.Sprintf("%s %s", keyword, strings.Join(parts, ", "))
+	return
+}
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithRecursiveBuild(t *testing.T) {
+	var base = New(nil, "SELECT id, parent_id, 0 AS depth FROM categories").
+		Where("id = ?", 1)
+	var step = New(nil, "SELECT c.id, c.parent_id, tree.depth + 1 FROM categories c JOIN tree ON c.parent_id = tree.id")
+
+	var b = New(nil, "SELECT * FROM tree").
+		WithRecursive("tree(id, parent_id, depth)", base, step).
+		Where("depth < ?", 5).
+		Limit(10).
+		Page(2)
+
+	queryString, countQuery := b.build()
+
+	var with = "WITH RECURSIVE tree(id, parent_id, depth) AS (SELECT id, parent_id, 0 AS depth FROM categories WHERE id = ? UNION ALL SELECT c.id, c.parent_id, tree.depth + 1 FROM categories c JOIN tree ON c.parent_id = tree.id)"
+	if !strings.HasPrefix(queryString, with) {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if !strings.HasSuffix(queryString, "LIMIT 10 OFFSET 10") {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if countQuery != with+" SELECT COUNT(1) FROM (SELECT * FROM tree WHERE depth < ?) t" {
+		t.Fatalf("unexpected count query: %s", countQuery)
+	}
+
+	var values = b.values()
+	if len(values) != 2 || values[0] != 1 || values[1] != 5 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
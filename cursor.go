@@ -0,0 +1,176 @@
+package query
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidCursor returned when a cursor token is tampered, signed with another key or for another order
+var ErrInvalidCursor = errors.New("query: invalid cursor")
+
+// CursorCodec sign, and optionally encrypt, the keyset cursors of After and Cursor
+type CursorCodec struct {
+	signKey []byte
+	aead    cipher.AEAD
+}
+
+type cursorPayload struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// NewCursorCodec sign cursors with signKey, and encrypt them with an AES encryptKey
+func NewCursorCodec(signKey []byte, encryptKey []byte) (*CursorCodec, error) {
+	if len(signKey) == 0 {
+		return nil, errors.New("query: empty cursor sign key")
+	}
+
+	var codec = &CursorCodec{signKey: signKey}
+	if len(encryptKey) > 0 {
+		block, err := aes.NewCipher(encryptKey)
+		if err != nil {
+			return nil, err
+		}
+		if codec.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return codec, nil
+}
+
+// Cursor token of the last row of the page, values are the row's values of the OrderByCol columns in order
+func (b *Builder) Cursor(codec *CursorCodec, values ...interface{}) (string, error) {
+	columns, spec, err := b.keyset()
+	if err != nil {
+		return "", err
+	}
+	if len(values) != len(columns) {
+		return "", fmt.Errorf("%w: %d values for %d sort columns", ErrInvalidCursor, len(values), len(columns))
+	}
+
+	data, err := json.Marshal(cursorPayload{Sort: spec, Values: values})
+	if err != nil {
+		return "", err
+	}
+
+	return codec.encode(data)
+}
+
+// After continue after the row of the cursor token, call it after the orders
+func (b *Builder) After(codec *CursorCodec, token string) *Builder {
+	columns, spec, err := b.keyset()
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+
+	data, err := codec.decode(token)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+
+	var payload cursorPayload
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil || payload.Sort != spec || len(payload.Values) != len(columns) {
+		b.fail(ErrInvalidCursor)
+		return b
+	}
+
+	// (a > ?) OR (a = ? AND b > ?) ... with < for descending columns
+	var ors = make([]string, 0, len(columns))
+	var args = make([]interface{}, 0, len(columns)*(len(columns)+1)/2)
+	for i, column := range columns {
+		var ands = make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, columns[j].expr+" = ?")
+			args = append(args, payload.Values[j])
+		}
+		var op = ">"
+		if column.dir == Desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", column.expr, op))
+		args = append(args, payload.Values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return b.Where("("+strings.Join(ors, " OR ")+")", args...)
+}
+
+// keyset typed sort columns of the builder and their spec signed into the cursors
+func (b *Builder) keyset() ([]sortTerm, string, error) {
+	if len(b.sorts) == 0 || b.orderBy != "" || len(b.rankOrderBy) > 0 {
+		return nil, "", fmt.Errorf("%w: cursors need OrderByCol columns only", ErrInvalidCursor)
+	}
+
+	var specs = make([]string, 0, len(b.sorts))
+	for _, sort := range b.sorts {
+		if sort.random || sort.lower || sort.collate != "" || sort.nulls != NullsDefault || !isIdentifier(sort.expr) {
+			return nil, "", fmt.Errorf("%w: cursors need OrderByCol columns only", ErrInvalidCursor)
+		}
+		specs = append(specs, sort.build(""))
+	}
+
+	return b.sorts, strings.Join(specs, ","), nil
+}
+
+// encode data, encrypted then signed
+func (c *CursorCodec) encode(data []byte) (string, error) {
+	if c.aead != nil {
+		var nonce = make([]byte, c.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		data = c.aead.Seal(nonce, nonce, data, nil)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(c.sign(data)), nil
+}
+
+// decode verify and decrypt the token
+func (c *CursorCodec) decode(token string) ([]byte, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(data)) {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.aead == nil {
+		return data, nil
+	}
+	if len(data) < c.aead.NonceSize() {
+		return nil, ErrInvalidCursor
+	}
+	if data, err = c.aead.Open(nil, data[:c.aead.NonceSize()], data[c.aead.NonceSize():], nil); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return data, nil
+}
+
+func (c *CursorCodec) sign(data []byte) []byte {
+	var mac = hmac.New(sha256.New, c.signKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	for _, encryptKey := range [][]byte{nil, []byte("0123456789abcdef")} {
+		codec, err := NewCursorCodec([]byte("secret"), encryptKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var feed = func() *Builder {
+			return New(nil, "SELECT * FROM events").
+				OrderByCol("created_at", Desc).
+				OrderByCol("id", Asc).
+				Limit(20)
+		}
+
+		token, err := feed().Cursor(codec, "2021-03-01T00:00:00Z", 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encryptKey != nil && strings.Contains(token, "eyJ") {
+			t.Fatalf("encrypted cursor is readable: %s", token)
+		}
+
+		var b = feed().After(codec, token)
+		if err := b.validate(); err != nil {
+			t.Fatal(err)
+		}
+		queryString, _ := b.build()
+		if queryString != "SELECT * FROM events WHERE ((created_at < ?) OR (created_at = ? AND id > ?)) ORDER BY created_at DESC,id ASC LIMIT 20" {
+			t.Fatalf("unexpected query: %s", queryString)
+		}
+		if values := b.values(); len(values) != 3 || values[0] != "2021-03-01T00:00:00Z" || values[2] != json.Number("42") {
+			t.Fatalf("unexpected values: %v", values)
+		}
+
+		var tampered = strings.Replace(token, token[:1], string(token[0]^1), 1)
+		if err := feed().After(codec, tampered).validate(); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for a tampered cursor, got %v", err)
+		}
+
+		var reordered = New(nil, "SELECT * FROM events").OrderByCol("created_at", Asc).OrderByCol("id", Asc)
+		if err := reordered.After(codec, token).validate(); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for another order, got %v", err)
+		}
+	}
+}
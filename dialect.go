@@ -0,0 +1,24 @@
+package query
+
+// Dialect names as reported by gorm dialectors
+const (
+	DialectPostgres  = "postgres"
+	DialectMySQL     = "mysql"
+	DialectSQLite    = "sqlite"
+	DialectSQLServer = "sqlserver"
+)
+
+// WithDialect override the dialect detected from the db handle
+func (b *Builder) WithDialect(dialect string) *Builder {
+	b.dialectName = dialect
+	return b
+}
+
+// dialect name of the underlying database
+func (b *Builder) dialect() string {
+	if b.dialectName != "" {
+		return b.dialectName
+	}
+
+	return b.executor().Dialect()
+}
@@ -0,0 +1,11 @@
+package query
+
+// Exec run f on the built query and return its result typed, without the reflection of ExecFunc
+func Exec[T any](b *Builder, f func(db DB, rawSQL DB) (T, error)) (T, error) {
+	var result T
+	var err = b.rawQuery(func(db DB, rawSQL DB) (err error) {
+		result, err = f(db, rawSQL)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,503 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Executor run built statements, converting the ? placeholders to its bindvars
+type Executor interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Dialect() string
+}
+
+// SQLConn database/sql handle, satisfied by *sql.DB, *sql.Tx and *sql.Conn
+type SQLConn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// RowsFunc read the rows of the data query, rows are closed by the caller
+type RowsFunc = func(rows *sql.Rows) (interface{}, error)
+
+// NewWithExecutor init a builder running on executor, ExecFunc, PagingFunc and Scan need gorm
+func NewWithExecutor(exec Executor, rawSQL string) *Builder {
+	var builder = New(nil, rawSQL)
+	builder.exec = exec
+	return builder
+}
+
+// executor of the builder, gorm unless NewWithExecutor was used
+func (b *Builder) executor() Executor {
+	if b.exec != nil {
+		return b.exec
+	}
+
+	return NewGormExecutor(b.db)
+}
+
+// NewGormExecutor executor on a gorm DB, e.g. to put gorm handles behind a ReplicaRouter
+func NewGormExecutor(db DB) Executor {
+	return &gormExecutor{db: db}
+}
+
+// Rows run the data query
+func (b *Builder) Rows(ctx context.Context) (*sql.Rows, error) {
+	sqlString, _ := b.build()
+	return b.queryRows(ctx, statement{sqlString, b.values()})
+}
+
+// ReadRows run the data query and read its rows with fn, the rows are closed once fn returns
+func (b *Builder) ReadRows(ctx context.Context, fn func(rows *sql.Rows) error) error {
+	sqlString, _ := b.build()
+	return b.readRows(ctx, statement{sqlString, b.values()}, fn)
+}
+
+// Count run the count query
+func (b *Builder) Count(ctx context.Context) (int, error) {
+	_, countSQLString := b.build()
+	return b.queryCount(ctx, statement{countSQLString, b.countValues()})
+}
+
+// PagingRows paging on the executor, f reads the rows of the current page into the records
+func (b *Builder) PagingRows(ctx context.Context, f RowsFunc) (*Pagination, error) {
+	if b.page < 1 {
+		b.page = 1
+	}
+
+	var paging = func() (*Pagination, error) {
+		sqlString, countSQLString := b.build()
+		countSQLString = b.summaryCountSQL(countSQLString)
+		values, countValues := b.boundValues()
+		pagination, err := b.pagingRows(ctx, statement{sqlString, values}, statement{countSQLString, countValues}, f)
+		if err != nil {
+			return nil, err
+		}
+
+		return pagination, b.attachOnSession(ctx, pagination)
+	}
+
+	pagination, err := b.sharedPaging(ctx, func() (*Pagination, error) {
+		if b.cache != nil {
+			sqlString, _ := b.build()
+			return b.cachedPaging(ctx, statement{sqlString, b.values()}, func() (*Pagination, error) {
+				return b.pageInRange(paging)
+			})
+		}
+
+		return b.pageInRange(paging)
+	})
+	if err == nil {
+		b.prefetchRows(ctx, pagination, f)
+	}
+
+	return pagination, err
+}
+
+// queryRows run a built data statement
+func (b *Builder) queryRows(ctx context.Context, stmt statement) (*sql.Rows, error) {
+	if err := b.beforeExec(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	// open rows can't hold a slot or keep a session alive
+	if b.maxConcurrent > 0 {
+		return nil, ErrRowsLimited
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if b.readOnlyTx {
+		return nil, ErrReadOnlySession
+	}
+	if schema, err := b.resolveSchema(ctx); err != nil || schema != "" {
+		if err == nil {
+			err = ErrSchemaSession
+		}
+		return nil, err
+	}
+
+	return b.reader(ctx).QueryContext(ctx, stmt.sql, stmt.values...)
+}
+
+// readRows run a built data statement in the session, holding the slot until fn has read the rows
+func (b *Builder) readRows(ctx context.Context, stmt statement, fn func(rows *sql.Rows) error) error {
+	if err := b.beforeExec(ctx, stmt); err != nil {
+		return err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return b.session(ctx, func(exec Executor, dedicated bool) error {
+		rows, err := exec.QueryContext(ctx, stmt.sql, stmt.values...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if err = fn(rows); err != nil {
+			return err
+		}
+		return rows.Err()
+	})
+}
+
+// queryCount run a built count statement
+func (b *Builder) queryCount(ctx context.Context, countStmt statement) (int, error) {
+	if err := b.beforeExec(ctx, countStmt); err != nil {
+		return 0, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	var count int
+	err = b.session(ctx, func(exec Executor, dedicated bool) error {
+		return exec.QueryRowContext(ctx, countStmt.sql, countStmt.values...).Scan(&count)
+	})
+	return count, err
+}
+
+// querySummary run a built count statement, with the summary aggregates of WithSummary
+func (b *Builder) querySummary(ctx context.Context, countStmt statement) (count int, summary map[string]interface{}, err error) {
+	if err = b.beforeExec(ctx, countStmt); err != nil {
+		return 0, nil, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+
+	err = b.session(ctx, func(exec Executor, dedicated bool) (err error) {
+		count, summary, err = scanSummary(exec.QueryContext(ctx, countStmt.sql, countStmt.values...))
+		return err
+	})
+	return count, summary, err
+}
+
+// pagingRows run built data and count statements and paginate the current page
+func (b *Builder) pagingRows(ctx context.Context, stmt statement, countStmt statement, f RowsFunc) (*Pagination, error) {
+	if err := b.beforeExec(ctx, stmt, countStmt); err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if b.lazyCount {
+		var result interface{}
+		err = b.session(ctx, func(exec Executor, dedicated bool) error {
+			rows, err := exec.QueryContext(ctx, stmt.sql, stmt.values...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			if result, err = f(rows); err != nil {
+				return err
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return b.paginateLazy(result, func(ctx context.Context) (int, map[string]interface{}, error) {
+			return b.querySummary(ctx, countStmt)
+		}), nil
+	}
+
+	var count int
+	var summary map[string]interface{}
+	var result interface{}
+	err = b.session(ctx, func(exec Executor, dedicated bool) error {
+		if b.batched() {
+			var err error
+			count, summary, result, err = b.batchedPaging(ctx, exec, stmt, countStmt, f)
+			return err
+		}
+
+		// a dedicated session is a single connection, the queries can't overlap
+		var done = make(chan error, 1)
+		var countQuery = func() {
+			if b.summary != "" {
+				var err error
+				count, summary, err = scanSummary(exec.QueryContext(ctx, countStmt.sql, countStmt.values...))
+				done <- err
+				return
+			}
+			done <- exec.QueryRowContext(ctx, countStmt.sql, countStmt.values...).Scan(&count)
+		}
+		if dedicated {
+			countQuery()
+		} else {
+			go countQuery()
+		}
+
+		rows, err := exec.QueryContext(ctx, stmt.sql, stmt.values...)
+		if err == nil {
+			result, err = f(rows)
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+
+		var countErr = <-done
+		if err != nil {
+			return err
+		}
+		return countErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pagination = b.paginate(count, result)
+	pagination.Summary = summary
+	return pagination, nil
+}
+
+type gormExecutor struct {
+	db DB
+}
+
+func (e *gormExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.db.WithContext(ctx).Raw(query, args...).Rows()
+}
+
+func (e *gormExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return e.db.WithContext(ctx).Raw(query, args...).Row()
+}
+
+func (e *gormExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var tx = e.db.WithContext(ctx).Exec(query, args...)
+	return rowsAffected(tx.RowsAffected), tx.Error
+}
+
+func (e *gormExecutor) Transaction(ctx context.Context, fn func(tx Executor) error) error {
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormExecutor(WrapGorm(tx)))
+	})
+}
+
+func (e *gormExecutor) Dialect() string {
+	if e.db == nil {
+		return ""
+	}
+
+	var tx = e.db.Unscoped()
+	if tx == nil || tx.Dialector == nil {
+		return ""
+	}
+
+	return tx.Dialector.Name()
+}
+
+type rowsAffected int64
+
+func (r rowsAffected) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("query: LastInsertId is not supported")
+}
+
+func (r rowsAffected) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+// SQLExecutor executor on a plain database/sql handle, without gorm
+type SQLExecutor struct {
+	conn    SQLConn
+	dialect string
+	stmts   *stmtCache
+}
+
+// NewSQLExecutor init an executor on conn, dialect selects the bindvars (DialectPostgres uses $1, $2...)
+func NewSQLExecutor(conn SQLConn, dialect string) *SQLExecutor {
+	return &SQLExecutor{
+		conn:    conn,
+		dialect: dialect,
+	}
+}
+
+// QueryContext ...
+func (e *SQLExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = Rebind(e.dialect, query, args)
+	if stmt := e.stmts.get(ctx, e.conn, query); stmt != nil {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return e.conn.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext ...
+func (e *SQLExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = Rebind(e.dialect, query, args)
+	if stmt := e.stmts.get(ctx, e.conn, query); stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return e.conn.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext ...
+func (e *SQLExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = Rebind(e.dialect, query, args)
+	return e.conn.ExecContext(ctx, query, args...)
+}
+
+// Transaction run fn in a transaction, or in the current one when conn is a *sql.Tx
+func (e *SQLExecutor) Transaction(ctx context.Context, fn func(tx Executor) error) error {
+	beginner, ok := e.conn.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return fn(e)
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(NewSQLExecutor(tx, e.dialect)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Dialect ...
+func (e *SQLExecutor) Dialect() string {
+	return e.dialect
+}
+
+// Rebind expand the ? placeholders like gorm and convert them to the dialect bindvars
+func Rebind(dialect string, query string, args []interface{}) (string, []interface{}) {
+	query, args = expandArgs(query, args, true)
+
+	var sb strings.Builder
+	var n = 0
+	scanPlaceholders(query, func(literal string, placeholder bool) {
+		sb.WriteString(literal)
+		if !placeholder {
+			return
+		}
+
+		n++
+		switch dialect {
+		case DialectPostgres:
+			fmt.Fprintf(&sb, "$%d", n)
+		case DialectSQLServer:
+			fmt.Fprintf(&sb, "@p%d", n)
+		default:
+			sb.WriteByte('?')
+		}
+	})
+
+	return sb.String(), args
+}
+
+// expandArgs inline clause.Expr args, and expand slices into (?, ?) lists when slices is set
+func expandArgs(query string, args []interface{}, slices bool) (string, []interface{}) {
+	var sb strings.Builder
+	var expanded = make([]interface{}, 0, len(args))
+	var index = 0
+	scanPlaceholders(query, func(literal string, placeholder bool) {
+		sb.WriteString(literal)
+		if !placeholder {
+			return
+		}
+		if index >= len(args) {
+			sb.WriteByte('?')
+			return
+		}
+
+		var arg = args[index]
+		index++
+
+		switch v := arg.(type) {
+		case clause.Expr:
+			sql, vars := expandArgs(v.SQL, v.Vars, slices)
+			sb.WriteString(sql)
+			expanded = append(expanded, vars...)
+			return
+		case driver.Valuer, []byte:
+			sb.WriteByte('?')
+			expanded = append(expanded, v)
+			return
+		}
+
+		var rv = reflect.ValueOf(arg)
+		if slices && arg != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) {
+			if rv.Len() == 0 {
+				sb.WriteString("(NULL)")
+				return
+			}
+			sb.WriteString("(")
+			for i := 0; i < rv.Len(); i++ {
+				if i > 0 {
+					sb.WriteString(",")
+				}
+				sb.WriteByte('?')
+				expanded = append(expanded, rv.Index(i).Interface())
+			}
+			sb.WriteString(")")
+			return
+		}
+
+		sb.WriteByte('?')
+		expanded = append(expanded, arg)
+	})
+
+	if index < len(args) {
+		expanded = append(expanded, args[index:]...)
+	}
+
+	return sb.String(), expanded
+}
+
+// scanPlaceholders call fn with each literal chunk, placeholder reports a ? follows the chunk
+func scanPlaceholders(query string, fn func(literal string, placeholder bool)) {
+	var start = 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		var c = query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			fn(query[start:i], true)
+			start = i + 1
+		}
+	}
+	fn(query[start:], false)
+}
@@ -0,0 +1,29 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	var query = "SELECT * FROM users u JOIN ? ON v.id = u.id WHERE u.kind IN ? AND u.note <> '?' AND u.tags && ?"
+	var args = []interface{}{
+		Values([][]interface{}{{1}, {2}}, "id"),
+		[]string{"a", "b"},
+		Array([]string{"x"}),
+	}
+
+	got, gotArgs := Rebind(DialectPostgres, query, args)
+	var want = "SELECT * FROM users u JOIN (VALUES ($1), ($2)) AS v(id) ON v.id = u.id WHERE u.kind IN ($3,$4) AND u.note <> '?' AND u.tags && $5"
+	if got != want {
+		t.Fatalf("unexpected query: %s", got)
+	}
+	if len(gotArgs) != 5 || !reflect.DeepEqual(gotArgs[:4], []interface{}{1, 2, "a", "b"}) {
+		t.Fatalf("unexpected args: %v", gotArgs)
+	}
+
+	got, _ = Rebind(DialectMySQL, "SELECT ? FROM dual WHERE id IN ?", []interface{}{1, []int{}})
+	if got != "SELECT ? FROM dual WHERE id IN (NULL)" {
+		t.Fatalf("unexpected query: %s", got)
+	}
+}
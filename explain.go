@@ -0,0 +1,194 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ErrExplainNotSupported returned when the dialect has no structured EXPLAIN output
+var ErrExplainNotSupported = errors.New("query: explain is not supported for this dialect")
+
+// Plan query plan returned by Explain
+type Plan struct {
+	Node          *PlanNode `json:"node"`
+	TotalCost     float64   `json:"total_cost"`
+	EstimatedRows float64   `json:"estimated_rows"`
+	ActualRows    float64   `json:"actual_rows"`
+	PlanningTime  float64   `json:"planning_time"`
+	ExecutionTime float64   `json:"execution_time"`
+	Analyzed      bool      `json:"analyzed"`
+	Raw           JSONRaw   `json:"-"`
+}
+
+// PlanNode node of a query plan
+type PlanNode struct {
+	NodeType      string      `json:"node_type"`
+	RelationName  string      `json:"relation_name,omitempty"`
+	IndexName     string      `json:"index_name,omitempty"`
+	StartupCost   float64     `json:"startup_cost"`
+	TotalCost     float64     `json:"total_cost"`
+	EstimatedRows float64     `json:"estimated_rows"`
+	ActualRows    float64     `json:"actual_rows"`
+	ActualLoops   float64     `json:"actual_loops"`
+	ActualTime    float64     `json:"actual_time"`
+	Plans         []*PlanNode `json:"plans,omitempty"`
+}
+
+type pgPlanNode struct {
+	NodeType        string        `json:"Node Type"`
+	RelationName    string        `json:"Relation Name"`
+	IndexName       string        `json:"Index Name"`
+	StartupCost     float64       `json:"Startup Cost"`
+	TotalCost       float64       `json:"Total Cost"`
+	PlanRows        float64       `json:"Plan Rows"`
+	ActualRows      float64       `json:"Actual Rows"`
+	ActualLoops     float64       `json:"Actual Loops"`
+	ActualTotalTime float64       `json:"Actual Total Time"`
+	Plans           []*pgPlanNode `json:"Plans"`
+}
+
+type pgPlan struct {
+	Plan          *pgPlanNode `json:"Plan"`
+	PlanningTime  float64     `json:"Planning Time"`
+	ExecutionTime float64     `json:"Execution Time"`
+}
+
+type mysqlPlan struct {
+	QueryBlock map[string]interface{} `json:"query_block"`
+}
+
+// Explain run EXPLAIN on the built query, analyze executes the statement
+func (b *Builder) Explain(ctx context.Context, analyze bool) (*Plan, error) {
+	sqlString, _ := b.build()
+	var stmt = statement{sqlString, b.values()}
+
+	if err := b.beforeExec(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	var plan *Plan
+	var err = b.session(ctx, func(exec Executor, dedicated bool) (err error) {
+		plan, err = b.explain(ctx, exec, stmt, analyze)
+		return err
+	})
+
+	return plan, err
+}
+
+func (b *Builder) explain(ctx context.Context, exec Executor, stmt statement, analyze bool) (*Plan, error) {
+	var explainSQL string
+	switch b.dialect() {
+	case DialectPostgres:
+		if analyze {
+			explainSQL = fmt.Sprintf("EXPLAIN (ANALYZE, FORMAT JSON) %s", stmt.sql)
+		} else {
+			explainSQL = fmt.Sprintf("EXPLAIN (FORMAT JSON) %s", stmt.sql)
+		}
+	case DialectMySQL:
+		// MySQL only supports tree output for EXPLAIN ANALYZE
+		explainSQL = fmt.Sprintf("EXPLAIN FORMAT=JSON %s", stmt.sql)
+		analyze = false
+	default:
+		return nil, ErrExplainNotSupported
+	}
+
+	var raw JSONRaw
+	var err = exec.QueryRowContext(ctx, explainSQL, stmt.values...).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan = Plan{
+		Analyzed: analyze,
+		Raw:      raw,
+	}
+
+	if b.dialect() == DialectMySQL {
+		var result mysqlPlan
+		if err = raw.Unmarshal(&result); err != nil {
+			return nil, err
+		}
+		if costInfo, ok := result.QueryBlock["cost_info"].(map[string]interface{}); ok {
+			plan.TotalCost = planNumber(costInfo["query_cost"])
+		}
+		plan.EstimatedRows = mysqlRows(result.QueryBlock)
+		return &plan, nil
+	}
+
+	var result []pgPlan
+	if err = jsoniter.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 || result[0].Plan == nil {
+		return nil, errors.New("query: empty explain output")
+	}
+
+	plan.Node = result[0].Plan.toPlanNode()
+	plan.TotalCost = plan.Node.TotalCost
+	plan.EstimatedRows = plan.Node.EstimatedRows
+	plan.ActualRows = plan.Node.ActualRows
+	plan.PlanningTime = result[0].PlanningTime
+	plan.ExecutionTime = result[0].ExecutionTime
+
+	return &plan, nil
+}
+
+func (n *pgPlanNode) toPlanNode() *PlanNode {
+	var node = &PlanNode{
+		NodeType:      n.NodeType,
+		RelationName:  n.RelationName,
+		IndexName:     n.IndexName,
+		StartupCost:   n.StartupCost,
+		TotalCost:     n.TotalCost,
+		EstimatedRows: n.PlanRows,
+		ActualRows:    n.ActualRows,
+		ActualLoops:   n.ActualLoops,
+		ActualTime:    n.ActualTotalTime,
+	}
+	for _, child := range n.Plans {
+		node.Plans = append(node.Plans, child.toPlanNode())
+	}
+
+	return node
+}
+
+// mysqlRows rows produced by the outermost table or nested loop of a MySQL JSON plan
+func mysqlRows(block map[string]interface{}) float64 {
+	if table, ok := block["table"].(map[string]interface{}); ok {
+		return planNumber(table["rows_produced_per_join"])
+	}
+
+	if loop, ok := block["nested_loop"].([]interface{}); ok && len(loop) > 0 {
+		if last, ok := loop[len(loop)-1].(map[string]interface{}); ok {
+			return mysqlRows(last)
+		}
+	}
+
+	// ordering_operation, grouping_operation and the like wrap the tables
+	for _, value := range block {
+		if child, ok := value.(map[string]interface{}); ok {
+			if rows := mysqlRows(child); rows > 0 {
+				return rows
+			}
+		}
+	}
+
+	return 0
+}
+
+// planNumber number of a MySQL JSON plan, costs are strings
+func planNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+
+	return 0
+}
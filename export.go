@@ -0,0 +1,207 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrExportKeyMissing returned when a parallel export has no key column to split on
+var ErrExportKeyMissing = errors.New("query: parallel export requires a key column")
+
+// ExportOptions CSV export options
+type ExportOptions struct {
+	// Header write the column names as first line
+	Header bool
+	// KeyColumn integer column splitting the export into ranges, as written in the conditions, e.g. "o.id"
+	KeyColumn string
+	// Segments key ranges exported concurrently, 0 or 1 exports sequentially
+	Segments int
+}
+
+// ExportCSV write every row to w as CSV, ignoring Limit and Page
+func (b *Builder) ExportCSV(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	var export = *b
+	export.limit = 0
+	export.page = 0
+
+	if opts.Segments <= 1 {
+		return export.exportAll(ctx, w, opts.Header)
+	}
+
+	if opts.KeyColumn == "" {
+		return ErrExportKeyMissing
+	}
+
+	low, high, err := export.keyRange(ctx, opts.KeyColumn)
+	if err != nil {
+		return err
+	}
+	// no rows to split, the header still comes from the columns of the query
+	if !low.Valid || !high.Valid {
+		return export.exportAll(ctx, w, opts.Header)
+	}
+
+	var segments = int64(opts.Segments)
+	var step = (high.Int64 - low.Int64 + segments) / segments
+
+	// segments are spooled to temp files, so the fast ones don't wait on the writer
+	var files = make([]*os.File, opts.Segments)
+	defer func() {
+		for _, file := range files {
+			if file != nil {
+				file.Close()
+				os.Remove(file.Name())
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var errs = make([]error, opts.Segments)
+	var columns []string
+	var once sync.Once
+	for i := range files {
+		if files[i], err = ioutil.TempFile("", "query-export-*.csv"); err != nil {
+			return err
+		}
+
+		var from = low.Int64 + int64(i)*step
+		var segment = export
+		segment.wheres = append(export.wheres[:len(export.wheres):len(export.wheres)], fmt.Sprintf("%s >= ? AND %s < ?", opts.KeyColumn, opts.KeyColumn))
+		segment.whereValues = append(export.whereValues[:len(export.whereValues):len(export.whereValues)], from, from+step)
+
+		wg.Add(1)
+		go func(i int, segment Builder) {
+			defer wg.Done()
+
+			var writer = csv.NewWriter(files[i])
+			segmentColumns, err := segment.exportSegment(ctx, writer, false)
+			if err == nil {
+				writer.Flush()
+				err = writer.Error()
+			}
+			if segmentColumns != nil {
+				once.Do(func() {
+					columns = segmentColumns
+				})
+			}
+			errs[i] = err
+		}(i, segment)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Header && columns != nil {
+		var writer = csv.NewWriter(w)
+		writer.Write(columns)
+		writer.Flush()
+		if err = writer.Error(); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportAll write the rows of the builder sequentially
+func (b *Builder) exportAll(ctx context.Context, w io.Writer, header bool) error {
+	var writer = csv.NewWriter(w)
+	if _, err := b.exportSegment(ctx, writer, header); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// keyRange MIN and MAX of the key column over the filtered rows
+func (b *Builder) keyRange(ctx context.Context, keyColumn string) (low sql.NullInt64, high sql.NullInt64, err error) {
+	var bounds = *b
+	bounds.selects = append(b.selects[:len(b.selects):len(b.selects)], fmt.Sprintf("%s AS export_key", keyColumn))
+
+	with, _ := bounds.buildWith()
+	var boundsSQL = wrapQuery(with, bounds.buildBody(), "MIN(t.export_key), MAX(t.export_key)")
+
+	err = bounds.readRows(ctx, statement{boundsSQL, bounds.countValues()}, func(rows *sql.Rows) error {
+		if rows.Next() {
+			return rows.Scan(&low, &high)
+		}
+		return nil
+	})
+	return
+}
+
+// exportSegment write the rows of the builder, returning the columns
+func (b *Builder) exportSegment(ctx context.Context, writer *csv.Writer, header bool) (columns []string, err error) {
+	err = b.ReadRows(ctx, func(rows *sql.Rows) error {
+		columns, err = writeRows(rows, writer, header)
+		return err
+	})
+	return columns, err
+}
+
+// writeRows write the rows as CSV, returning the columns
+func writeRows(rows *sql.Rows, writer *csv.Writer, header bool) ([]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if header {
+		if err = writer.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+
+	var values = make([]interface{}, len(columns))
+	var pointers = make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	var record = make([]string, len(columns))
+	for rows.Next() {
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			record[i] = csvValue(value)
+		}
+		if err = writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	return columns, rows.Err()
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,118 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// Facets grouped counts by column and value, NULL values are counted under "null"
+type Facets map[string]map[string]int
+
+func init() {
+	// cached pages keep their facets typed
+	RegisterCacheType(Facets{})
+}
+
+// WithFacets attach the counts of the filtered rows grouped by each column as Facets metadata
+func (b *Builder) WithFacets(columns ...string) *Builder {
+	for _, column := range columns {
+		if !isIdentifier(column) {
+			b.fail(fmt.Errorf("query: invalid facet column %q", column))
+			return b
+		}
+	}
+
+	b.facets = append(b.facets, columns...)
+	return b
+}
+
+// facetStatements build the grouped count statement of each facet column
+func (b *Builder) facetStatements() []statement {
+	var body = b.buildBody()
+	with, _ := b.buildWith()
+	var hint = b.hintComment()
+	var countValues = b.countValues()
+
+	var statements = make([]statement, 0, len(b.facets))
+	for _, column := range b.facets {
+		var sqlString = hint + wrapQuery(with, body, column+", COUNT(1)") + " GROUP BY " + column
+		statements = append(statements, statement{sqlString, countValues})
+	}
+	return statements
+}
+
+// attachFacets run the facet queries and attach the counts to the pagination
+func (b *Builder) attachFacets(pagination *Pagination, parallel bool, query func(stmt statement) (*sql.Rows, error)) error {
+	if len(b.facets) == 0 || pagination == nil {
+		return nil
+	}
+
+	var statements = b.facetStatements()
+	var counts = make([]map[string]int, len(statements))
+	var errs = make([]error, len(statements))
+
+	var wg sync.WaitGroup
+	for i := range statements {
+		var run = func(i int) {
+			defer wg.Done()
+			counts[i], errs[i] = scanFacet(query(statements[i]))
+		}
+
+		wg.Add(1)
+		if parallel {
+			go run(i)
+		} else {
+			run(i)
+		}
+	}
+	wg.Wait()
+
+	var facets = make(Facets, len(b.facets))
+	for i, column := range b.facets {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		facets[column] = counts[i]
+	}
+
+	pagination.Metadata = facets
+	return nil
+}
+
+// attachOnSession run the facet queries on the session of the builder and attach the time window
+func (b *Builder) attachOnSession(ctx context.Context, pagination *Pagination) error {
+	b.attachWindow(pagination)
+	if len(b.facets) == 0 || pagination == nil {
+		return nil
+	}
+
+	return b.session(ctx, func(exec Executor, dedicated bool) error {
+		return b.attachFacets(pagination, !dedicated, func(stmt statement) (*sql.Rows, error) {
+			return exec.QueryContext(ctx, stmt.sql, stmt.values...)
+		})
+	})
+}
+
+func scanFacet(rows *sql.Rows, err error) (map[string]int, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts = map[string]int{}
+	for rows.Next() {
+		var value sql.NullString
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		if !value.Valid {
+			value.String = "null"
+		}
+		counts[value.String] += count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,73 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// flightCall in-flight paging shared by the callers of the same key
+type flightCall struct {
+	done       chan struct{}
+	pagination *Pagination
+	err        error
+}
+
+var flights = struct {
+	sync.Mutex
+	calls map[string]*flightCall
+}{calls: map[string]*flightCall{}}
+
+// Singleflight share one paging between concurrent callers of the same key and query, Records must not be modified
+func (b *Builder) Singleflight(key string) *Builder {
+	b.flight = key
+	return b
+}
+
+// sharedPaging run paging once for the concurrent callers of the same query
+func (b *Builder) sharedPaging(ctx context.Context, paging func() (*Pagination, error)) (*Pagination, error) {
+	// the lazy totals fill the Pagination of one caller only
+	if b.flight == "" || b.lazyCount {
+		return paging()
+	}
+
+	scope, err := b.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sqlString, _ := b.build()
+	var key = fmt.Sprintf("%s;%s;%s;%#v;%d;%d", b.flight, scope, sqlString, b.values(), b.limit, b.page)
+
+	flights.Lock()
+	if call, ok := flights.calls[key]; ok {
+		flights.Unlock()
+		select {
+		case <-call.done:
+			return call.result()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var call = &flightCall{done: make(chan struct{})}
+	flights.calls[key] = call
+	flights.Unlock()
+
+	call.pagination, call.err = paging()
+
+	flights.Lock()
+	delete(flights.calls, key)
+	flights.Unlock()
+	close(call.done)
+
+	return call.result()
+}
+
+func (c *flightCall) result() (*Pagination, error) {
+	if c.pagination == nil {
+		return nil, c.err
+	}
+
+	var pagination = *c.pagination
+	return &pagination, c.err
+}
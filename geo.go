@@ -0,0 +1,25 @@
+package query
+
+import (
+	"fmt"
+)
+
+// geoPoint WGS 84 point from bound longitude and latitude
+const geoPoint = "ST_SetSRID(ST_MakePoint(?, ?), 4326)"
+
+// WhereWithinRadius filter rows whose PostGIS column lies within meters of the point
+func (b *Builder) WhereWithinRadius(column string, lat float64, lng float64, meters float64) *Builder {
+	return b.Where(fmt.Sprintf("ST_DWithin(%s::geography, %s::geography, ?)", column, geoPoint), lng, lat, meters)
+}
+
+// OrderByDistance order by distance to the point, nearest first
+func (b *Builder) OrderByDistance(column string, lat float64, lng float64) *Builder {
+	b.rankOrderBy = append(b.rankOrderBy, fmt.Sprintf("%s <-> %s", column, geoPoint))
+	b.orderValues = append(b.orderValues, lng, lat)
+	return b
+}
+
+// WithDistance select the distance in meters to the lat/lng point as alias
+func (b *Builder) WithDistance(column string, lat float64, lng float64, alias string) *Builder {
+	return b.AddSelect(fmt.Sprintf("ST_Distance(%s::geography, %s::geography) AS %s", column, geoPoint, alias), lng, lat)
+}
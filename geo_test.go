@@ -0,0 +1,30 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestGeoNearest(t *testing.T) {
+	var b = New(nil, "SELECT s.id, s.name FROM stores s").
+		WhereWithinRadius("s.location", 10.77, 106.7, 5000).
+		WithDistance("s.location", 10.77, 106.7, "distance").
+		OrderByDistance("s.location", 10.77, 106.7).
+		Limit(20)
+
+	queryString, countQuery := b.build()
+	var want = "SELECT s.id, s.name, ST_Distance(s.location::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography) AS distance FROM stores s WHERE ST_DWithin(s.location::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?) ORDER BY s.location <-> ST_SetSRID(ST_MakePoint(?, ?), 4326) LIMIT 20"
+	if queryString != want {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if len(b.values()) != 7 {
+		t.Fatalf("unexpected values: %v", b.values())
+	}
+
+	// the count query has no ORDER BY, so it must not receive the order values
+	if len(b.countValues()) != 5 {
+		t.Fatalf("unexpected count values for %s: %v", countQuery, b.countValues())
+	}
+	if b.values()[0] != 106.7 {
+		t.Fatalf("longitude must come first: %v", b.values())
+	}
+}
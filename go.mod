@@ -1,8 +1,11 @@
 module github.com/thaitanloi365/go-query
 
-go 1.15
+go 1.18
 
 require (
+	github.com/jackc/pgconn v1.8.0
+	github.com/jackc/pgx/v4 v4.10.1
+	github.com/jmoiron/sqlx v1.3.1
 	github.com/json-iterator/go v1.1.10
 	gorm.io/datatypes v1.0.0
 	gorm.io/driver/postgres v1.0.8
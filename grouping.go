@@ -0,0 +1,101 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type groupingKind int
+
+const (
+	groupingRollup groupingKind = iota + 1
+	groupingCube
+	groupingSets
+)
+
+type grouping struct {
+	kind groupingKind
+	sets [][]string
+}
+
+// GroupingID value of GROUPING(col1, ..., colN) in a row, a bit is set for each rolled up column
+type GroupingID int
+
+// IsSubtotal report whether the row aggregates over at least one of the grouped columns
+func (g GroupingID) IsSubtotal() bool {
+	return g != 0
+}
+
+// IsGrandTotal report whether the row aggregates over all n grouped columns
+func (g GroupingID) IsGrandTotal(n int) bool {
+	return int(g) == 1<<n-1
+}
+
+// IsRolledUp report whether column i of n is rolled up in the row
+func (g GroupingID) IsRolledUp(i int, n int) bool {
+	return g&(1<<(n-1-i)) != 0
+}
+
+// Grouping GROUPING(cols...) AS alias projection, scan it into a GroupingID to tell subtotal rows apart
+func Grouping(alias string, cols ...string) string {
+	return fmt.Sprintf("GROUPING(%s) AS %s", strings.Join(cols, ", "), alias)
+}
+
+// GroupByRollup group by ROLLUP (cols...), producing subtotals from right to left
+func (b *Builder) GroupByRollup(cols ...string) *Builder {
+	b.grouping = &grouping{kind: groupingRollup, sets: [][]string{cols}}
+	return b
+}
+
+// GroupByCube group by CUBE (cols...), producing subtotals for every combination
+func (b *Builder) GroupByCube(cols ...string) *Builder {
+	b.grouping = &grouping{kind: groupingCube, sets: [][]string{cols}}
+	return b
+}
+
+// GroupByGroupingSets group by GROUPING SETS ((set1...), (set2...)), an empty set is the grand total
+func (b *Builder) GroupByGroupingSets(sets ...[]string) *Builder {
+	b.grouping = &grouping{kind: groupingSets, sets: sets}
+	return b
+}
+
+// buildGroupBy combine GroupBy with the grouping specification
+func (b *Builder) buildGroupBy() string {
+	if b.grouping == nil {
+		return b.groupBy
+	}
+
+	var expr string
+	switch b.grouping.kind {
+	case groupingRollup:
+		if b.dialect() == DialectMySQL {
+			// MySQL only supports the trailing modifier, which can't be mixed with plain columns
+			return fmt.Sprintf("%s WITH ROLLUP", strings.Join(append(splitNonEmpty(b.groupBy), b.grouping.sets[0]...), ", "))
+		}
+		expr = fmt.Sprintf("ROLLUP (%s)", strings.Join(b.grouping.sets[0], ", "))
+	case groupingCube:
+		expr = fmt.Sprintf("CUBE (%s)", strings.Join(b.grouping.sets[0], ", "))
+	case groupingSets:
+		var sets = []string{}
+		for _, set := range b.grouping.sets {
+			sets = append(sets, fmt.Sprintf("(%s)", strings.Join(set, ", ")))
+		}
+		expr = fmt.Sprintf("GROUPING SETS (%s)", strings.Join(sets, ", "))
+	}
+
+	if b.groupBy == "" {
+		return expr
+	}
+
+	return fmt.Sprintf("%s, %s", b.groupBy, expr)
+}
+
+func splitNonEmpty(s string) []string {
+	var parts = []string{}
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,38 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestGroupByRollup(t *testing.T) {
+	var b = New(nil, "SELECT region, product, SUM(amount) FROM sales").
+		WithDialect(DialectPostgres).
+		GroupBy("year").
+		GroupByRollup("region", "product")
+	if got := b.buildGroupBy(); got != "year, ROLLUP (region, product)" {
+		t.Fatalf("unexpected group by: %s", got)
+	}
+
+	b.WithDialect(DialectMySQL)
+	if got := b.buildGroupBy(); got != "year, region, product WITH ROLLUP" {
+		t.Fatalf("unexpected group by: %s", got)
+	}
+}
+
+func TestGroupByGroupingSets(t *testing.T) {
+	var b = New(nil, "SELECT region, product, SUM(amount) FROM sales").
+		GroupByGroupingSets([]string{"region"}, []string{"product"}, []string{})
+	if got := b.buildGroupBy(); got != "GROUPING SETS ((region), (product), ())" {
+		t.Fatalf("unexpected group by: %s", got)
+	}
+}
+
+func TestGroupingID(t *testing.T) {
+	var g = GroupingID(2) // GROUPING(region, product) with region rolled up
+	if !g.IsSubtotal() || !g.IsRolledUp(0, 2) || g.IsRolledUp(1, 2) || g.IsGrandTotal(2) {
+		t.Fatalf("unexpected flags for %d", g)
+	}
+	if !GroupingID(3).IsGrandTotal(2) {
+		t.Fatalf("3 should be the grand total of 2 columns")
+	}
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HandlerPolicy what clients of a Handler can request
+type HandlerPolicy struct {
+	// Sortable columns of the sort param, e.g. sort=-created_at,name
+	Sortable []string
+	// DefaultSort order without sort param
+	DefaultSort []Order
+	// Filters param name to condition with a single placeholder, e.g. "status": "status = ?"
+	Filters map[string]string
+	// DefaultLimit page size without limit param, default 20
+	DefaultLimit int
+	// MaxLimit largest limit param, larger limits are clamped, default 100
+	MaxLimit int
+	// Envelope wrap the pagination in an object under this key, e.g. {"data": {...}}
+	Envelope string
+}
+
+// Handler serve the paginated query of factory as JSON, invalid params are answered with 400
+func Handler(factory func(r *http.Request) *Builder, policy HandlerPolicy) http.Handler {
+	if policy.DefaultLimit <= 0 {
+		policy.DefaultLimit = 20
+	}
+	if policy.MaxLimit <= 0 {
+		policy.MaxLimit = 100
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b = factory(r)
+		if err := policy.apply(b, r); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		pagination, err := b.PagingRows(r.Context(), func(rows *sql.Rows) (interface{}, error) {
+			return scanRows(rows)
+		})
+		switch {
+		case errors.Is(err, ErrInvalidOrder), errors.Is(err, ErrPageOutOfRange), errors.Is(err, ErrInvalidLimit):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": http.StatusText(http.StatusInternalServerError)})
+			return
+		}
+
+		if policy.Envelope != "" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{policy.Envelope: pagination})
+			return
+		}
+		writeJSON(w, http.StatusOK, pagination)
+	})
+}
+
+// apply the request params to the builder
+func (p HandlerPolicy) apply(b *Builder, r *http.Request) error {
+	var params = r.URL.Query()
+
+	var page, limit = 1, p.DefaultLimit
+	if s := params.Get("page"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid page %q", s)
+		}
+		page = n
+	}
+	if s := params.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid limit %q", s)
+		}
+		limit = n
+	}
+	if limit > p.MaxLimit {
+		limit = p.MaxLimit
+	}
+	b.Page(page).Limit(limit)
+
+	// sorted so the same params build the same SQL
+	var names = make([]string, 0, len(p.Filters))
+	for name := range p.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value, ok := params[name]; ok {
+			b.Where(p.Filters[name], value[0])
+		}
+	}
+
+	var orders = p.DefaultSort
+	if s := params.Get("sort"); s != "" {
+		orders = nil
+		for _, column := range strings.Split(s, ",") {
+			var order = Order{Col: column, Dir: Asc}
+			if strings.HasPrefix(column, "-") {
+				order = Order{Col: column[1:], Dir: Desc}
+			}
+			orders = append(orders, order)
+		}
+		b.AllowSort(p.Sortable...)
+	}
+
+	b.OrderByColumns(orders...)
+	return b.err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// health of the db handles and executors checked by HealthCheck
+var health sync.Map
+
+// HealthCheck ping db with SELECT 1 and record its health for Degraded
+func HealthCheck(ctx context.Context, db interface{}) error {
+	var exec Executor
+	switch db := db.(type) {
+	case Executor:
+		exec = db
+	case DB:
+		exec = NewGormExecutor(db)
+	default:
+		return fmt.Errorf("query: health check of unsupported %T", db)
+	}
+
+	var one int
+	var err = exec.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	if pool := poolOf(db); reflect.TypeOf(pool).Comparable() {
+		health.Store(pool, err == nil)
+	}
+	return err
+}
+
+// isHealthy report the health of the handle, healthy until a check fails
+func isHealthy(handle interface{}) bool {
+	if handle == nil || !reflect.TypeOf(handle).Comparable() {
+		return true
+	}
+
+	healthy, ok := health.Load(handle)
+	return !ok || healthy.(bool)
+}
+
+// Degraded serve the last good page, marked {"stale": true}, for staleTTL when the db fails, call after Cached
+func (b *Builder) Degraded(staleTTL time.Duration) *Builder {
+	b.degraded = staleTTL
+	return b
+}
+
+// staleKey key of the last good page, independent of the tag generations so invalidated pages stay available
+func staleKey(ctx context.Context, b *Builder, stmt statement) (string, error) {
+	scope, err := b.scope(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var hash = sha256.Sum256([]byte(fmt.Sprintf("%s;%s;%#v;%d;%d", scope, stmt.sql, stmt.values, b.limit, b.page)))
+	return "query:stale:" + hex.EncodeToString(hash[:]), nil
+}
+
+// stalePage the last good page marked stale
+func (b *Builder) stalePage(ctx context.Context, stmt statement) (*Pagination, bool) {
+	key, err := staleKey(ctx, b, stmt)
+	if err != nil {
+		return nil, false
+	}
+
+	data, ok, err := b.cache.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	pagination, ok := b.decodePage(data)
+	if !ok {
+		return nil, false
+	}
+
+	var metadata = map[string]interface{}{"stale": true}
+	if pagination.Metadata != nil {
+		metadata["metadata"] = pagination.Metadata
+	}
+	pagination.Metadata = metadata
+	return pagination, true
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UseIndex hint the indexes of the first table in FROM, on postgres with the pg_hint_plan extension
+func (b *Builder) UseIndex(indexes ...string) *Builder {
+	b.indexes = append(b.indexes, indexes...)
+	return b
+}
+
+// applyIndexHint insert the MySQL and SQL Server table hints after the first table reference
+func (b *Builder) applyIndexHint(rawSQL string) string {
+	if len(b.indexes) == 0 {
+		return rawSQL
+	}
+
+	var clause string
+	switch b.dialect() {
+	case DialectMySQL:
+		clause = fmt.Sprintf("USE INDEX (%s)", strings.Join(b.indexes, ", "))
+	case DialectSQLServer:
+		clause = fmt.Sprintf("WITH (INDEX(%s))", strings.Join(b.indexes, ", "))
+	default:
+		return rawSQL
+	}
+
+	at, _, ok := firstTable(rawSQL)
+	if !ok {
+		return rawSQL
+	}
+
+	return fmt.Sprintf("%s %s%s", rawSQL[:at], clause, rawSQL[at:])
+}
+
+// hintComment pg_hint_plan comment, which must lead the statement
+func (b *Builder) hintComment() string {
+	if len(b.indexes) == 0 || b.dialect() != DialectPostgres {
+		return ""
+	}
+
+	_, name, ok := firstTable(b.RawSQLString)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("/*+ IndexScan(%s %s) */ ", name, strings.Join(b.indexes, " "))
+}
@@ -0,0 +1,48 @@
+package query
+
+import "testing"
+
+func TestUseIndex(t *testing.T) {
+	var tests = []struct {
+		dialect string
+		rawSQL  string
+		want    string
+	}{
+		{
+			dialect: DialectMySQL,
+			rawSQL:  "SELECT * FROM orders o WHERE o.status = 1",
+			want:    "SELECT * FROM orders o USE INDEX (idx_orders_created_at) WHERE o.status = 1",
+		},
+		{
+			dialect: DialectSQLServer,
+			rawSQL:  "SELECT * FROM orders",
+			want:    "SELECT * FROM orders WITH (INDEX(idx_orders_created_at))",
+		},
+		{
+			dialect: DialectPostgres,
+			rawSQL:  "SELECT * FROM orders o JOIN users u ON u.id = o.user_id",
+			want:    "/*+ IndexScan(o idx_orders_created_at) */ SELECT * FROM orders o JOIN users u ON u.id = o.user_id",
+		},
+		{
+			dialect: DialectMySQL,
+			rawSQL:  "SELECT EXTRACT(YEAR FROM o.created_at) AS year FROM orders o",
+			want:    "SELECT EXTRACT(YEAR FROM o.created_at) AS year FROM orders o USE INDEX (idx_orders_created_at)",
+		},
+	}
+
+	for _, test := range tests {
+		var b = New(nil, test.rawSQL).WithDialect(test.dialect).UseIndex("idx_orders_created_at")
+		queryString, countQuery := b.build()
+		if queryString != test.want {
+			t.Errorf("got %s, want %s", queryString, test.want)
+		}
+		if test.dialect == DialectPostgres && countQuery != "/*+ IndexScan(o idx_orders_created_at) */ SELECT COUNT(1) FROM (SELECT * FROM orders o JOIN users u ON u.id = o.user_id) t" {
+			t.Errorf("unexpected count query: %s", countQuery)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM orders").WithDialect(DialectSQLServer).UseIndex("idx").Sample(SampleSystem, 10)
+	if queryString, _ := b.build(); queryString != "SELECT * FROM orders TABLESAMPLE SYSTEM (10 PERCENT) WITH (INDEX(idx))" {
+		t.Errorf("unexpected query: %s", queryString)
+	}
+}
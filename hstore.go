@@ -0,0 +1,147 @@
+package query
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Hstore postgres hstore column, NULL values are mapped to empty strings
+type Hstore map[string]string
+
+// Value ...
+func (h Hstore) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	var keys = make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs = make([]string, 0, len(h))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=>%s", quoteHstore(key), quoteHstore(h[key])))
+	}
+
+	return strings.Join(pairs, ", "), nil
+}
+
+// Scan ...
+func (h *Hstore) Scan(src interface{}) error {
+	var source string
+	switch v := src.(type) {
+	case nil:
+		*h = nil
+		return nil
+	case string:
+		source = v
+	case []byte:
+		source = string(v)
+	default:
+		return errors.New("Incompatible type for Hstore")
+	}
+
+	var result = Hstore{}
+	var p = hstoreParser{s: source}
+	for {
+		p.skipSpaces()
+		if p.done() {
+			break
+		}
+
+		key, _, err := p.token()
+		if err != nil {
+			return err
+		}
+
+		p.skipSpaces()
+		if !strings.HasPrefix(p.s[p.i:], "=>") {
+			return fmt.Errorf("query: invalid hstore %q", source)
+		}
+		p.i += 2
+		p.skipSpaces()
+
+		value, _, err := p.token()
+		if err != nil {
+			return err
+		}
+		result[key] = value
+
+		p.skipSpaces()
+		if !p.done() {
+			if p.s[p.i] != ',' {
+				return fmt.Errorf("query: invalid hstore %q", source)
+			}
+			p.i++
+		}
+	}
+
+	*h = result
+	return nil
+}
+
+func quoteHstore(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return fmt.Sprintf(`"%s"`, s)
+}
+
+type hstoreParser struct {
+	s string
+	i int
+}
+
+func (p *hstoreParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *hstoreParser) skipSpaces() {
+	for !p.done() && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n') {
+		p.i++
+	}
+}
+
+// token read a quoted string or a bare word, null reports an unquoted NULL
+func (p *hstoreParser) token() (value string, null bool, err error) {
+	if p.done() {
+		return "", false, errors.New("query: unexpected end of hstore")
+	}
+
+	if p.s[p.i] != '"' {
+		var start = p.i
+		for !p.done() && p.s[p.i] != ',' && p.s[p.i] != '=' && p.s[p.i] != ' ' {
+			p.i++
+		}
+		value = p.s[start:p.i]
+		if strings.EqualFold(value, "NULL") {
+			return "", true, nil
+		}
+		return value, false, nil
+	}
+
+	var sb strings.Builder
+	p.i++
+	for !p.done() {
+		var c = p.s[p.i]
+		switch c {
+		case '\\':
+			p.i++
+			if !p.done() {
+				sb.WriteByte(p.s[p.i])
+			}
+		case '"':
+			p.i++
+			return sb.String(), false, nil
+		default:
+			sb.WriteByte(c)
+		}
+		p.i++
+	}
+
+	return "", false, errors.New("query: unterminated hstore string")
+}
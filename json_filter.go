@@ -0,0 +1,62 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WhereJSONContains filter rows whose json column contains value, column is not escaped
+func (b *Builder) WhereJSONContains(column string, value interface{}) *Builder {
+	data, err := json.Marshal(value)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+
+	if b.dialect() == DialectMySQL {
+		return b.Where(fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), string(data))
+	}
+
+	return b.Where(fmt.Sprintf("%s @> ?::jsonb", column), string(data))
+}
+
+// WhereJSONKey filter rows whose json key equals value as text, nil matches missing keys
+func (b *Builder) WhereJSONKey(column string, key string, value interface{}) *Builder {
+	var expr = fmt.Sprintf("%s ->> ?", column)
+	var keyArg interface{} = key
+	if b.dialect() == DialectMySQL {
+		expr = fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?))", column)
+		keyArg = fmt.Sprintf(`$."%s"`, key)
+	}
+
+	if value == nil {
+		return b.Where(fmt.Sprintf("%s IS NULL", expr), keyArg)
+	}
+
+	return b.Where(fmt.Sprintf("%s = ?", expr), keyArg, fmt.Sprint(value))
+}
+
+// WhereJSONHasKey filter rows whose json column has the top level key
+func (b *Builder) WhereJSONHasKey(column string, key string) *Builder {
+	if b.dialect() == DialectMySQL {
+		return b.Where(fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', ?)", column), fmt.Sprintf(`$."%s"`, key))
+	}
+
+	// jsonb_exists is the function behind the ? operator, which would clash with placeholders
+	return b.Where(fmt.Sprintf("jsonb_exists(%s, ?)", column), key)
+}
+
+// WhereJSONPath filter rows matching a postgres jsonpath predicate, vars are bound as $name in the path
+func (b *Builder) WhereJSONPath(column string, path string, vars map[string]interface{}) *Builder {
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(vars)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+
+	return b.Where(fmt.Sprintf("jsonb_path_exists(%s, ?::jsonpath, ?::jsonb)", column), path, string(data))
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestJSONFilters(t *testing.T) {
+	var b = New(nil, "SELECT * FROM accounts").
+		WithDialect(DialectPostgres).
+		WhereJSONContains("metadata", map[string]interface{}{"plan": "pro"}).
+		WhereJSONKey("metadata", "seats", 5).
+		WhereJSONHasKey("metadata", "trial").
+		WhereJSONPath("metadata", "$.seats ? (@ > $min)", map[string]interface{}{"min": 1})
+
+	queryString, _ := b.build()
+	var want = "SELECT * FROM accounts WHERE metadata @> ?::jsonb AND metadata ->> ? = ? AND jsonb_exists(metadata, ?) AND jsonb_path_exists(metadata, ?::jsonpath, ?::jsonb)"
+	if queryString != want {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	var values = b.values()
+	if len(values) != 6 || values[0] != `{"plan":"pro"}` || values[2] != "5" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+func TestJSONFilterMarshalError(t *testing.T) {
+	var b = New(nil, "SELECT * FROM accounts").WhereJSONContains("metadata", map[string]interface{}{"plan": make(chan int)})
+	if err := b.validate(); err == nil {
+		t.Fatalf("expected the marshal error")
+	}
+
+	b = New(nil, "SELECT * FROM accounts").WhereJSONPath("metadata", "$.seats ? (@ > $min)", map[string]interface{}{"min": func() {}})
+	if err := b.validate(); err == nil {
+		t.Fatalf("expected the marshal error")
+	}
+}
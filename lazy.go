@@ -0,0 +1,67 @@
+package query
+
+import (
+	"context"
+	"sync"
+)
+
+type lazyTotal struct {
+	mu    sync.Mutex
+	done  bool
+	count func(ctx context.Context) (int, error)
+}
+
+// WithLazyCount skip the count query, Pagination.Total runs it on demand
+func (b *Builder) WithLazyCount() *Builder {
+	b.lazyCount = true
+	return b
+}
+
+// paginateLazy pagination of the current page, counting on the first call to Total
+func (b *Builder) paginateLazy(result interface{}, count func(ctx context.Context) (int, map[string]interface{}, error)) *Pagination {
+	var pagination = b.paginate(0, result)
+
+	var page, limit, strict = b.page, b.limit, b.strict
+	pagination.total = &lazyTotal{
+		count: func(ctx context.Context) (int, error) {
+			total, summary, err := count(ctx)
+			if err != nil {
+				return 0, err
+			}
+
+			// only the fields depending on the count are filled, the rest of the page is kept
+			var counted = (&Builder{page: page, limit: limit, strict: strict}).paginate(total, pagination.Records)
+			pagination.TotalRecord = counted.TotalRecord
+			pagination.TotalPage = counted.TotalPage
+			pagination.NextPage = counted.NextPage
+			pagination.PrevPage = counted.PrevPage
+			pagination.HasNext = counted.HasNext
+			pagination.HasPrev = counted.HasPrev
+			pagination.Summary = summary
+			return total, nil
+		},
+	}
+
+	return pagination
+}
+
+// Total number of records, lazy paginations run the count until it succeeds
+func (p *Pagination) Total(ctx context.Context) (int, error) {
+	var total = p.total
+	if total == nil {
+		return p.TotalRecord, nil
+	}
+
+	total.mu.Lock()
+	defer total.mu.Unlock()
+
+	// failures, e.g. a canceled ctx, aren't kept so a later call can count
+	if !total.done {
+		if _, err := total.count(ctx); err != nil {
+			return 0, err
+		}
+		total.done = true
+	}
+
+	return p.TotalRecord, nil
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrTooManyConcurrent returned by fail fast builders when the handle already runs its maximum of queries
+var ErrTooManyConcurrent = errors.New("query: too many concurrent queries")
+
+// ErrRowsLimited returned by Rows on builders with WithMaxConcurrent, open rows can't hold the slot
+var ErrRowsLimited = errors.New("query: open rows can't hold a concurrency slot, use ReadRows")
+
+// RateLimiter throttles the executions of a builder, e.g. a *rate.Limiter of golang.org/x/time/rate
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+type concurrencyLimit struct {
+	slots chan struct{}
+	users int
+}
+
+// semaphores per connection pool, kept while limited builders use them
+var concurrencyLimits = struct {
+	sync.Mutex
+	handles map[interface{}]*concurrencyLimit
+}{handles: map[interface{}]*concurrencyLimit{}}
+
+// WithMaxConcurrent run at most n limited queries at once per connection pool
+func (b *Builder) WithMaxConcurrent(n int, failFast bool) *Builder {
+	var handle = b.handle()
+	if handle == nil || n <= 0 || !reflect.TypeOf(handle).Comparable() {
+		return b
+	}
+
+	b.maxConcurrent = n
+	b.failFast = failFast
+	return b
+}
+
+// useLimit semaphore of the handle, done must be called once the slot is released
+func (b *Builder) useLimit() (limit *concurrencyLimit, done func()) {
+	var handle = b.handle()
+
+	concurrencyLimits.Lock()
+	defer concurrencyLimits.Unlock()
+
+	limit = concurrencyLimits.handles[handle]
+	if limit == nil {
+		limit = &concurrencyLimit{slots: make(chan struct{}, b.maxConcurrent)}
+		concurrencyLimits.handles[handle] = limit
+	}
+	limit.users++
+
+	return limit, func() {
+		concurrencyLimits.Lock()
+		defer concurrencyLimits.Unlock()
+
+		if limit.users--; limit.users == 0 {
+			delete(concurrencyLimits.handles, handle)
+		}
+	}
+}
+
+// WithRateLimit wait for the limiter before each execution
+func (b *Builder) WithRateLimit(limiter RateLimiter) *Builder {
+	b.rateLimit = limiter
+	return b
+}
+
+// handle connection pool of the builder's db handle or executor
+func (b *Builder) handle() interface{} {
+	if b.exec != nil {
+		return poolOf(b.exec)
+	}
+	if b.db != nil {
+		return poolOf(b.db)
+	}
+	return nil
+}
+
+// poolOf the *sql.DB behind a db handle or executor, unknown handles are their own pool
+func poolOf(handle interface{}) interface{} {
+	switch h := handle.(type) {
+	case *gormExecutor:
+		return poolOf(h.db)
+	case *SQLExecutor:
+		if h.conn != nil {
+			return poolOf(h.conn)
+		}
+	case *SQLXExecutor:
+		return poolOf(h.conn)
+	case *sqlx.DB:
+		return h.DB
+	case interface{ DB() *sql.DB }:
+		return h.DB()
+	case DB:
+		if gdb := h.GetGorm(); gdb != nil {
+			if sqlDB, err := gdb.DB(); err == nil {
+				return sqlDB
+			}
+			return gdb.Config
+		}
+	}
+
+	return handle
+}
+
+// acquire a slot of the concurrency limit, release must be called once the queries are done
+func (b *Builder) acquire(ctx context.Context) (release func(), err error) {
+	if b.rateLimit != nil {
+		if err = b.rateLimit.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.maxConcurrent <= 0 {
+		return func() {}, nil
+	}
+
+	limit, done := b.useLimit()
+	release = func() {
+		<-limit.slots
+		done()
+	}
+
+	if b.failFast {
+		select {
+		case limit.slots <- struct{}{}:
+			return release, nil
+		default:
+			done()
+			return nil, ErrTooManyConcurrent
+		}
+	}
+
+	select {
+	case limit.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
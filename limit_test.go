@@ -0,0 +1,98 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestWithMaxConcurrent(t *testing.T) {
+	var exec = NewSQLExecutor(nil, DialectPostgres)
+	var first = NewWithExecutor(exec, "SELECT 1").WithMaxConcurrent(1, true)
+	var second = NewWithExecutor(exec, "SELECT 2").WithMaxConcurrent(1, true)
+
+	release, err := first.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = second.acquire(context.Background()); !errors.Is(err, ErrTooManyConcurrent) {
+		t.Fatalf("expected ErrTooManyConcurrent, got %v", err)
+	}
+
+	release()
+	release, err = second.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	if _, ok := concurrencyLimits.handles[exec]; ok {
+		t.Fatalf("unused limit should be dropped")
+	}
+	if _, err = first.Rows(context.Background()); !errors.Is(err, ErrRowsLimited) {
+		t.Fatalf("expected ErrRowsLimited, got %v", err)
+	}
+}
+
+func TestWithMaxConcurrentPool(t *testing.T) {
+	sqlDB, err := sql.Open("pgx", "postgres://localhost/unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: gormlogger.Discard, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrappers made per request share the limit of the pool
+	var ctx = context.Background()
+	var first = New(WrapGorm(gdb.WithContext(ctx)), "SELECT 1").WithMaxConcurrent(1, true)
+	var second = NewWithExecutor(NewGormExecutor(WrapGorm(gdb.WithContext(ctx))), "SELECT 2").WithMaxConcurrent(1, true)
+
+	release, err := first.acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	if _, err = second.acquire(ctx); !errors.Is(err, ErrTooManyConcurrent) {
+		t.Fatalf("expected ErrTooManyConcurrent, got %v", err)
+	}
+}
+
+type countingLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var limiter = &countingLimiter{}
+	var b = NewWithExecutor(NewSQLExecutor(nil, DialectPostgres), "SELECT 1").WithRateLimit(limiter)
+
+	release, err := b.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	limiter.err = context.DeadlineExceeded
+	if _, err = b.acquire(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the limiter error, got %v", err)
+	}
+	if limiter.waits != 2 {
+		t.Fatalf("unexpected waits: %d", limiter.waits)
+	}
+}
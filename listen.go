@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Invalidator drops cached data when notified, e.g. by a Listener
+type Invalidator interface {
+	Invalidate(channel string, payload string)
+}
+
+// InvalidatorFunc adapt a func to Invalidator
+type InvalidatorFunc func(channel string, payload string)
+
+// Invalidate call f
+func (f InvalidatorFunc) Invalidate(channel string, payload string) {
+	f(channel, payload)
+}
+
+// Listener postgres LISTEN subscriber dispatching notifications to invalidators
+type Listener struct {
+	dsn          string
+	retryDelay   time.Duration
+	mutex        sync.RWMutex
+	invalidators map[string][]Invalidator
+	wake         context.CancelFunc
+}
+
+// NewListener init a listener connecting to dsn with its own connection
+func NewListener(dsn string) *Listener {
+	return &Listener{
+		dsn:          dsn,
+		retryDelay:   time.Second,
+		invalidators: map[string][]Invalidator{},
+	}
+}
+
+// WithRetryDelay delay before reconnecting after the connection is lost
+func (l *Listener) WithRetryDelay(delay time.Duration) *Listener {
+	l.retryDelay = delay
+	return l
+}
+
+// Subscribe invalidate when channel fires
+func (l *Listener) Subscribe(channel string, invalidators ...Invalidator) *Listener {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var _, listening = l.invalidators[channel]
+	l.invalidators[channel] = append(l.invalidators[channel], invalidators...)
+	if !listening && l.wake != nil {
+		l.wake()
+	}
+	return l
+}
+
+// InvalidateCache drop the pages cached under the tags, the channel by default, when channel fires
+func (l *Listener) InvalidateCache(channel string, tags ...string) *Listener {
+	if len(tags) == 0 {
+		tags = []string{channel}
+	}
+	return l.Subscribe(channel, CacheInvalidator(tags...))
+}
+
+// unsubscribe remove the invalidator from channel, the channel is unlistened once it has none
+func (l *Listener) unsubscribe(channel string, invalidator Invalidator) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var invalidators = l.invalidators[channel]
+	for i := range invalidators {
+		if invalidators[i] == invalidator {
+			l.invalidators[channel] = append(invalidators[:i:i], invalidators[i+1:]...)
+			break
+		}
+	}
+
+	if len(l.invalidators[channel]) == 0 {
+		delete(l.invalidators, channel)
+		if l.wake != nil {
+			l.wake()
+		}
+	}
+}
+
+// Notify dispatch a notification in process, e.g. from the writer, without a round trip to postgres
+func (l *Listener) Notify(channel string, payload string) {
+	l.dispatch(channel, payload)
+}
+
+// Run listen until ctx is done, invalidating every subscriber after a reconnect
+func (l *Listener) Run(ctx context.Context) error {
+	var reconnect bool
+	for {
+		var err = l.listen(ctx, reconnect)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(l.retryDelay):
+			}
+		}
+		reconnect = true
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, reconnect bool) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	defer func() {
+		l.mutex.Lock()
+		l.wake = nil
+		l.mutex.Unlock()
+	}()
+
+	var listening = map[string]bool{}
+	for {
+		// subscriptions changing the channels wake the wait up, cancelling it leaves the connection open
+		l.mutex.Lock()
+		var channels = make(map[string]bool, len(l.invalidators))
+		for channel := range l.invalidators {
+			channels[channel] = true
+		}
+		waitCtx, wake := context.WithCancel(ctx)
+		l.wake = wake
+		l.mutex.Unlock()
+
+		if err = l.listenTo(ctx, conn, listening, channels); err != nil {
+			wake()
+			return err
+		}
+		if reconnect {
+			for channel := range channels {
+				l.dispatch(channel, "")
+			}
+			reconnect = false
+		}
+
+		notification, err := conn.WaitForNotification(waitCtx)
+		wake()
+		if err != nil {
+			if waitCtx.Err() != nil && ctx.Err() == nil {
+				continue
+			}
+			return err
+		}
+		l.dispatch(notification.Channel, notification.Payload)
+	}
+}
+
+// listenTo LISTEN the new channels and UNLISTEN the dropped ones
+func (l *Listener) listenTo(ctx context.Context, conn *pgx.Conn, listening map[string]bool, channels map[string]bool) error {
+	for channel := range channels {
+		if listening[channel] {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+		listening[channel] = true
+	}
+
+	for channel := range listening {
+		if channels[channel] {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "UNLISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+		delete(listening, channel)
+	}
+
+	return nil
+}
+
+func (l *Listener) dispatch(channel string, payload string) {
+	l.mutex.RLock()
+	var invalidators = l.invalidators[channel]
+	l.mutex.RUnlock()
+
+	for _, invalidator := range invalidators {
+		invalidator.Invalidate(channel, payload)
+	}
+}
@@ -0,0 +1,20 @@
+package query
+
+import "testing"
+
+func TestListenerUnsubscribe(t *testing.T) {
+	var listener = NewListener("")
+	var first, second = &watcher{fired: make(chan struct{}, 1)}, &watcher{fired: make(chan struct{}, 1)}
+	listener.Subscribe("orders", first, second)
+
+	listener.unsubscribe("orders", first)
+	listener.Notify("orders", "")
+	if len(first.fired) != 0 || len(second.fired) != 1 {
+		t.Fatalf("only the subscribed watcher should fire")
+	}
+
+	listener.unsubscribe("orders", second)
+	if _, ok := listener.invalidators["orders"]; ok {
+		t.Fatalf("emptied channel should be dropped")
+	}
+}
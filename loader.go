@@ -0,0 +1,170 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoaderOptions batching of a Loader, zero values use the defaults
+type LoaderOptions struct {
+	// Wait collect keys for this long before running the batch, default 2ms
+	Wait time.Duration
+	// MaxBatch run the batch early once it has this many keys, default 500
+	MaxBatch int
+}
+
+// Loader batch the keys loaded within Wait into one IN query, create one per request
+type Loader[K comparable, V any] struct {
+	ctx     context.Context
+	builder func() *Builder
+	column  string
+	scan    func(rows *sql.Rows) (K, V, error)
+	options LoaderOptions
+
+	mu     sync.Mutex
+	batch  *loaderBatch[K, V]
+	loaded map[K]*loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys []K
+	once sync.Once
+	done chan struct{}
+	rows map[K][]V
+	err  error
+}
+
+// NewLoader create a loader filtering the builders by column
+func NewLoader[K comparable, V any](ctx context.Context, builder func() *Builder, column string, scan func(rows *sql.Rows) (K, V, error), options ...LoaderOptions) *Loader[K, V] {
+	var l = &Loader[K, V]{
+		ctx:     ctx,
+		builder: builder,
+		column:  column,
+		scan:    scan,
+		loaded:  map[K]*loaderBatch[K, V]{},
+	}
+	if len(options) > 0 {
+		l.options = options[0]
+	}
+	if l.options.Wait <= 0 {
+		l.options.Wait = 2 * time.Millisecond
+	}
+	if l.options.MaxBatch <= 0 {
+		l.options.MaxBatch = 500
+	}
+
+	return l
+}
+
+// Load the rows of key, batched with the keys loaded concurrently
+func (l *Loader[K, V]) Load(ctx context.Context, key K) ([]V, error) {
+	var batch = l.enqueue(key)
+
+	select {
+	case <-batch.done:
+		return batch.rows[key], batch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LoadMany the rows of each key, in one batch when possible
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([][]V, error) {
+	var batches = make([]*loaderBatch[K, V], len(keys))
+	for i, key := range keys {
+		batches[i] = l.enqueue(key)
+	}
+
+	var rows = make([][]V, len(keys))
+	for i, batch := range batches {
+		select {
+		case <-batch.done:
+			if batch.err != nil {
+				return nil, batch.err
+			}
+			rows[i] = batch.rows[keys[i]]
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return rows, nil
+}
+
+// enqueue add key to the pending batch, unless it's already loaded or pending
+func (l *Loader[K, V]) enqueue(key K) *loaderBatch[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if batch, ok := l.loaded[key]; ok {
+		return batch
+	}
+
+	if l.batch == nil {
+		var batch = &loaderBatch[K, V]{done: make(chan struct{})}
+		l.batch = batch
+		time.AfterFunc(l.options.Wait, func() { l.dispatch(batch) })
+	}
+
+	var batch = l.batch
+	batch.keys = append(batch.keys, key)
+	l.loaded[key] = batch
+	if len(batch.keys) >= l.options.MaxBatch {
+		l.batch = nil
+		go l.dispatch(batch)
+	}
+
+	return batch
+}
+
+// dispatch run the batch query once
+func (l *Loader[K, V]) dispatch(batch *loaderBatch[K, V]) {
+	batch.once.Do(func() {
+		l.mu.Lock()
+		if l.batch == batch {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+
+		batch.rows, batch.err = l.fetch(batch.keys)
+		if batch.err != nil {
+			// failed keys are fetched again by the next load
+			l.mu.Lock()
+			for _, key := range batch.keys {
+				delete(l.loaded, key)
+			}
+			l.mu.Unlock()
+		}
+		close(batch.done)
+	})
+}
+
+// fetch run one query for the keys and group the rows by key
+func (l *Loader[K, V]) fetch(keys []K) (map[K][]V, error) {
+	if !isIdentifier(l.column) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidColumn, l.column)
+	}
+
+	// the LIMIT and OFFSET of a list builder would cut the rows of the later keys
+	var b = l.builder().Limit(0).Page(0)
+
+	var result = make(map[K][]V, len(keys))
+	var err = b.Where(l.column+" IN ?", keys).ReadRows(l.ctx, func(rows *sql.Rows) error {
+		for rows.Next() {
+			key, value, err := l.scan(rows)
+			if err != nil {
+				return err
+			}
+			result[key] = append(result[key], value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// LockFunc runs while the advisory lock is held
+type LockFunc = func(tx *gorm.DB) error
+
+// ErrLockNotAcquired returned when the advisory lock can not be acquired
+var ErrLockNotAcquired = errors.New("query: advisory lock not acquired")
+
+// WithAdvisoryLock run fn in a transaction holding the advisory lock key (postgres, MySQL)
+func WithAdvisoryLock(ctx context.Context, db *gorm.DB, key string, fn LockFunc) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		switch tx.Dialector.Name() {
+		case DialectPostgres:
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+				return err
+			}
+
+			return fn(tx)
+
+		case DialectMySQL:
+			var acquired sql.NullInt64
+			if err := tx.Raw("SELECT GET_LOCK(?, -1)", key).Row().Scan(&acquired); err != nil {
+				return err
+			}
+			if !acquired.Valid || acquired.Int64 != 1 {
+				return ErrLockNotAcquired
+			}
+			defer tx.Exec("SELECT RELEASE_LOCK(?)", key)
+
+			return fn(tx)
+
+		default:
+			return fmt.Errorf("query: advisory lock is not supported for %s", tx.Dialector.Name())
+		}
+	})
+}
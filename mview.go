@@ -0,0 +1,152 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMaterializedViewNotSet returned when a materialized view helper is used without WithMaterializedView
+var ErrMaterializedViewNotSet = errors.New("query: materialized view not set")
+
+// ErrMaterializedViewParams returned when the view definition has bound parameters, DDL can't bind them
+var ErrMaterializedViewParams = errors.New("query: materialized view definition can't have parameters")
+
+// RefreshPolicy materialized view refresh policy
+type RefreshPolicy struct {
+	// MaxAge refresh the view before reading when the last refresh is older, 0 leaves it to schedulers
+	MaxAge time.Duration
+	// Concurrently refresh without locking out readers, the view needs a unique index
+	Concurrently bool
+}
+
+type materializedView struct {
+	name       string
+	definition string
+	policy     RefreshPolicy
+}
+
+// last refresh time per view name, shared by all builders of the process
+var materializedViewRefreshes sync.Map
+
+// refreshCall in-flight refresh shared by the concurrent readers of a stale view
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+var refreshFlights = struct {
+	sync.Mutex
+	calls map[string]*refreshCall
+}{calls: map[string]*refreshCall{}}
+
+// WithMaterializedView read from a materialized view of the raw SQL, call it right after New without values
+func (b *Builder) WithMaterializedView(name string, policy RefreshPolicy) *Builder {
+	var definition strings.Builder
+	definition.WriteString(b.RawSQLString)
+	b.writeWhere(&definition)
+
+	var placeholders bool
+	scanPlaceholders(definition.String(), func(literal string, placeholder bool) {
+		placeholders = placeholders || placeholder
+	})
+	if placeholders || len(b.whereValues) > 0 || len(b.namedWhereValues) > 0 {
+		b.fail(ErrMaterializedViewParams)
+		return b
+	}
+
+	b.mview = &materializedView{
+		name:       name,
+		definition: definition.String(),
+		policy:     policy,
+	}
+	b.RawSQLString = fmt.Sprintf("SELECT * FROM %s", name)
+	b.wheres = nil
+	return b
+}
+
+// CreateMaterializedView create the view from its definition if it doesn't exist
+func (b *Builder) CreateMaterializedView(ctx context.Context) error {
+	if b.mview == nil {
+		return ErrMaterializedViewNotSet
+	}
+
+	var stmt = fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS %s", b.mview.name, b.mview.definition)
+	if err := checkSingleStatement(stmt); err != nil {
+		return err
+	}
+	if _, err := b.executor().ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	materializedViewRefreshes.Store(b.mview.name, time.Now())
+	return nil
+}
+
+// RefreshMaterializedView refresh the view, meant to be called by schedulers
+func (b *Builder) RefreshMaterializedView(ctx context.Context, concurrently bool) error {
+	if b.mview == nil {
+		return ErrMaterializedViewNotSet
+	}
+
+	var stmt = fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", b.mview.name)
+	if concurrently {
+		stmt = fmt.Sprintf("REFRESH MATERIALIZED VIEW CONCURRENTLY %s", b.mview.name)
+	}
+
+	if _, err := b.executor().ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	materializedViewRefreshes.Store(b.mview.name, time.Now())
+	return nil
+}
+
+// refreshIfStale refresh the view when it is older than the policy MaxAge
+func (b *Builder) refreshIfStale(ctx context.Context) error {
+	if b.mview == nil || b.mview.policy.MaxAge <= 0 {
+		return nil
+	}
+
+	if b.fresh() {
+		return nil
+	}
+
+	refreshFlights.Lock()
+	if call, ok := refreshFlights.calls[b.mview.name]; ok {
+		refreshFlights.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	// refreshed since the check, by a call which just finished
+	if b.fresh() {
+		refreshFlights.Unlock()
+		return nil
+	}
+
+	var call = &refreshCall{done: make(chan struct{})}
+	refreshFlights.calls[b.mview.name] = call
+	refreshFlights.Unlock()
+
+	call.err = b.RefreshMaterializedView(ctx, b.mview.policy.Concurrently)
+
+	refreshFlights.Lock()
+	delete(refreshFlights.calls, b.mview.name)
+	refreshFlights.Unlock()
+	close(call.done)
+
+	return call.err
+}
+
+// fresh report a refresh of the view within the policy MaxAge
+func (b *Builder) fresh() bool {
+	last, ok := materializedViewRefreshes.Load(b.mview.name)
+	return ok && time.Since(last.(time.Time)) < b.mview.policy.MaxAge
+}
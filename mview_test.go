@@ -0,0 +1,23 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaterializedViewParams(t *testing.T) {
+	var b = New(nil, "SELECT * FROM orders").Where("deleted_at IS NULL").WithMaterializedView("orders_view", RefreshPolicy{})
+	if b.err != nil || b.mview.definition != "SELECT * FROM orders WHERE deleted_at IS NULL" || b.RawSQLString != "SELECT * FROM orders_view" {
+		t.Fatalf("unexpected view %+v: %v", b.mview, b.err)
+	}
+
+	for _, b := range []*Builder{
+		New(nil, "SELECT * FROM orders").Where("status = ?", "paid"),
+		New(nil, "SELECT * FROM orders WHERE status = ?"),
+		New(nil, "SELECT * FROM orders WHERE status = @status").WhereNamed("status", "paid"),
+	} {
+		if err := b.WithMaterializedView("orders_view", RefreshPolicy{}).validate(); !errors.Is(err, ErrMaterializedViewParams) {
+			t.Fatalf("expected ErrMaterializedViewParams, got %v", err)
+		}
+	}
+}
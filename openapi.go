@@ -0,0 +1,201 @@
+package query
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema OpenAPI 3 schema object
+type Schema = map[string]interface{}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawJSONType       = reflect.TypeOf(json.RawMessage{})
+	paginationType    = reflect.TypeOf(Pagination{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// PaginationSchema OpenAPI 3 schema of the Pagination of record, wrapped by envelope
+func PaginationSchema(record interface{}, envelope string) Schema {
+	var schema = typeSchema(paginationType, map[reflect.Type]bool{})
+	schema["properties"].(Schema)["records"] = Schema{
+		"type":  "array",
+		"items": typeSchema(reflect.TypeOf(record), map[reflect.Type]bool{}),
+	}
+
+	if envelope == "" {
+		return schema
+	}
+
+	return Schema{
+		"type":       "object",
+		"properties": Schema{envelope: schema},
+		"required":   []string{envelope},
+	}
+}
+
+// typeSchema schema of a Go type following encoding/json, seen holds the structs in progress
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	if t == nil {
+		return Schema{}
+	}
+
+	var nullable bool
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema Schema
+	switch {
+	case t == timeType:
+		schema = Schema{"type": "string", "format": "date-time"}
+	case t == rawJSONType || t == reflect.TypeOf(JSONRaw{}):
+		schema = Schema{}
+	case implements(t, jsonMarshalerType):
+		// custom JSON can be any value
+		schema = Schema{}
+	case implements(t, textMarshalerType):
+		schema = Schema{"type": "string"}
+	default:
+		schema = kindSchema(t, seen)
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+
+	return schema
+}
+
+// implements report whether t or *t implements iface, like encoding/json checks addressable values
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func kindSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		// int and uint are 64 bits on the supported platforms
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return Schema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return Schema{"type": "number", "format": "double"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		return structSchema(t, seen)
+	}
+
+	// interface{} is any value
+	return Schema{}
+}
+
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	// recursive types stop at a plain object
+	if seen[t] {
+		return Schema{"type": "object"}
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	var properties = Schema{}
+	var required = []string{}
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		var name, opts string
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ = strings.Cut(tag, ",")
+		}
+
+		// untagged embedded structs are flattened by encoding/json, nil pointers omit their fields
+		var embedded, pointer = field.Type, false
+		if embedded.Kind() == reflect.Ptr {
+			embedded, pointer = embedded.Elem(), true
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct && embedded != timeType {
+			var schema = structSchema(embedded, seen)
+			if schema["properties"] == nil {
+				continue
+			}
+			for k, v := range schema["properties"].(Schema) {
+				properties[k] = v
+			}
+			if !pointer {
+				required = append(required, schema["required"].([]string)...)
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, seen)
+		if hasOption(opts, "string") && quotable(field.Type) {
+			var schema = Schema{"type": "string"}
+			if field.Type.Kind() == reflect.Ptr {
+				schema["nullable"] = true
+			}
+			properties[name] = schema
+		}
+		if !hasOption(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return Schema{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// hasOption report whether the json tag options have option
+func hasOption(opts string, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// quotable report the types encoding/json quotes with the ,string option
+func quotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaginationSchema(t *testing.T) {
+	type user struct {
+		ID        int64      `json:"id"`
+		Name      string     `json:"name"`
+		Email     *string    `json:"email,omitempty"`
+		CreatedAt time.Time  `json:"created_at"`
+		DeletedAt *time.Time `json:"deleted_at"`
+		Tags      []string   `json:"tags"`
+		password  string
+	}
+
+	var schema = PaginationSchema(user{}, "data")
+	var pagination = schema["properties"].(Schema)["data"].(Schema)
+	var properties = pagination["properties"].(Schema)
+	if properties["total_record"].(Schema)["type"] != "integer" || properties["has_next"].(Schema)["type"] != "boolean" {
+		t.Fatalf("unexpected pagination properties: %v", properties)
+	}
+	for _, name := range pagination["required"].([]string) {
+		if name == "streamed" || name == "summary" {
+			t.Fatalf("omitempty field %s is required", name)
+		}
+	}
+
+	var records = properties["records"].(Schema)
+	var item = records["items"].(Schema)["properties"].(Schema)
+	if records["type"] != "array" || len(item) != 6 {
+		t.Fatalf("unexpected records schema: %v", records)
+	}
+	if item["id"].(Schema)["format"] != "int64" || item["created_at"].(Schema)["format"] != "date-time" ||
+		item["deleted_at"].(Schema)["nullable"] != true || item["tags"].(Schema)["items"].(Schema)["type"] != "string" {
+		t.Fatalf("unexpected record properties: %v", item)
+	}
+}
+
+type schemaNode struct {
+	Name     string        `json:"name"`
+	Parent   *schemaNode   `json:"parent"`
+	Children []*schemaNode `json:"children"`
+}
+
+type schemaAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+func TestPaginationSchemaRecursive(t *testing.T) {
+	type record struct {
+		*schemaAudit
+		schemaNode
+	}
+
+	var item = PaginationSchema(record{}, "")["properties"].(Schema)["records"].(Schema)["items"].(Schema)
+	var properties = item["properties"].(Schema)
+	if properties["created_by"] == nil || properties["name"] == nil {
+		t.Fatalf("embedded structs should be flattened: %v", properties)
+	}
+	for _, name := range item["required"].([]string) {
+		if name == "created_by" {
+			t.Fatalf("fields of an embedded pointer aren't required")
+		}
+	}
+	if properties["parent"].(Schema)["type"] != "object" || properties["parent"].(Schema)["properties"] != nil {
+		t.Fatalf("recursive type should stop at an object: %v", properties["parent"])
+	}
+}
+
+type schemaStatus int
+
+func (s schemaStatus) MarshalText() ([]byte, error) {
+	return []byte("active"), nil
+}
+
+type schemaPoint struct {
+	X, Y float64
+}
+
+func (p *schemaPoint) MarshalJSON() ([]byte, error) {
+	return []byte("[0, 0]"), nil
+}
+
+func TestPaginationSchemaEncoding(t *testing.T) {
+	type record struct {
+		Count    int          `json:"count"`
+		Small    int16        `json:"small"`
+		Amount   int64        `json:"amount,string"`
+		Optional *float64     `json:"optional,omitempty,string"`
+		Status   schemaStatus `json:"status"`
+		Location schemaPoint  `json:"location"`
+	}
+
+	var schema = PaginationSchema(record{}, "")
+	if schema["properties"].(Schema)["total_record"].(Schema)["format"] != "int64" {
+		t.Fatalf("int should be int64: %v", schema["properties"])
+	}
+
+	var properties = schema["properties"].(Schema)["records"].(Schema)["items"].(Schema)["properties"].(Schema)
+	if properties["count"].(Schema)["format"] != "int64" || properties["small"].(Schema)["format"] != "int32" {
+		t.Fatalf("unexpected integer formats: %v", properties)
+	}
+	if properties["amount"].(Schema)["type"] != "string" || properties["optional"].(Schema)["type"] != "string" || properties["optional"].(Schema)["nullable"] != true {
+		t.Fatalf("string option ignored: %v", properties)
+	}
+	if properties["status"].(Schema)["type"] != "string" || len(properties["location"].(Schema)) != 0 {
+		t.Fatalf("marshalers ignored: %v", properties)
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOrder returned when ordering by a column which isn't an identifier, or with an unknown direction
+var ErrInvalidOrder = errors.New("query: invalid order")
+
+// Direction sort direction
+type Direction string
+
+// Sort directions
+const (
+	Asc  Direction = "ASC"
+	Desc Direction = "DESC"
+)
+
+// NullsOrder placement of NULL values
+type NullsOrder int
+
+// Null placements, NullsDefault keeps the database default (postgres: NULLs are largest, MySQL: smallest)
+const (
+	NullsDefault NullsOrder = iota
+	NullsFirst
+	NullsLast
+)
+
+// Order typed ORDER BY column
+type Order struct {
+	Col         string
+	Dir         Direction
+	Nulls       NullsOrder
+	Collate     string
+	Insensitive bool
+}
+
+// OrderByCol append the column to the ORDER BY, after OrderBy
+func (b *Builder) OrderByCol(column string, dir Direction, nulls ...NullsOrder) *Builder {
+	var order = Order{Col: column, Dir: dir}
+	if len(nulls) > 0 {
+		order.Nulls = nulls[0]
+	}
+
+	return b.OrderByColumns(order)
+}
+
+// OrderByCollate append the column sorted with the collation to the ORDER BY
+func (b *Builder) OrderByCollate(column string, collation string, dir Direction) *Builder {
+	return b.OrderByColumns(Order{Col: column, Dir: dir, Collate: collation})
+}
+
+// OrderByInsensitive append LOWER(column) to the ORDER BY
+func (b *Builder) OrderByInsensitive(column string, dir Direction) *Builder {
+	return b.OrderByColumns(Order{Col: column, Dir: dir, Insensitive: true})
+}
+
+// OrderByColumns append the columns to the ORDER BY in order, after OrderBy
+func (b *Builder) OrderByColumns(orders ...Order) *Builder {
+	for _, order := range orders {
+		var term = sortTerm{expr: order.Col, dir: order.Dir, nulls: order.Nulls, collate: order.Collate, lower: order.Insensitive}
+		if err := term.validate(); err != nil {
+			b.fail(err)
+			return b
+		}
+		if b.sortable != nil && !b.sortable[order.Col] {
+			b.fail(fmt.Errorf("%w: column %q is not sortable", ErrInvalidOrder, order.Col))
+			return b
+		}
+
+		b.addSort(term)
+	}
+
+	return b
+}
+
+// AllowSort restrict the columns of OrderByCol, call it before adding the orders
+func (b *Builder) AllowSort(columns ...string) *Builder {
+	if b.sortable == nil {
+		b.sortable = map[string]bool{}
+	}
+	for _, column := range columns {
+		b.sortable[column] = true
+	}
+	return b
+}
+
+// validate reject columns which aren't identifiers, they would be formatted into the SQL
+func (t sortTerm) validate() error {
+	if !isIdentifier(t.expr) {
+		return fmt.Errorf("%w: column %q", ErrInvalidOrder, t.expr)
+	}
+	if t.dir != "" && t.dir != Asc && t.dir != Desc {
+		return fmt.Errorf("%w: direction %q", ErrInvalidOrder, t.dir)
+	}
+	if t.collate != "" && !isCollation(t.collate) {
+		return fmt.Errorf("%w: collation %q", ErrInvalidOrder, t.collate)
+	}
+	return nil
+}
+
+// build render the term for the dialect
+func (t sortTerm) build(dialect string) string {
+	if t.random {
+		return randomOrder(dialect, t.expr)
+	}
+
+	var expr = t.expr
+	if t.lower {
+		expr = fmt.Sprintf("LOWER(%s)", expr)
+	}
+	if t.collate != "" {
+		expr = fmt.Sprintf("%s COLLATE %s", expr, quoteCollation(t.collate, dialect))
+	}
+	if t.dir != "" {
+		expr = fmt.Sprintf("%s %s", expr, t.dir)
+	}
+
+	switch t.nulls {
+	case NullsFirst, NullsLast:
+		if dialect == DialectMySQL || dialect == DialectSQLServer {
+			var first, last = 0, 1
+			if t.nulls == NullsLast {
+				first, last = 1, 0
+			}
+			return fmt.Sprintf("CASE WHEN %s IS NULL THEN %d ELSE %d END,%s", t.expr, first, last, expr)
+		}
+		if t.nulls == NullsFirst {
+			return expr + " NULLS FIRST"
+		}
+		return expr + " NULLS LAST"
+	}
+
+	return expr
+}
+
+// isIdentifier report a possibly qualified identifier, e.g. "o.created_at"
+func isIdentifier(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCollation report a collation name, ICU locales like "de-DE-x-icu" have dashes
+func isCollation(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) && s[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteCollation quote the collation for the dialect, postgres collations are case sensitive identifiers
+func quoteCollation(collation string, dialect string) string {
+	if dialect == DialectPostgres {
+		return `"` + collation + `"`
+	}
+	return collation
+}
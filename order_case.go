@@ -0,0 +1,50 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CaseOrder ORDER BY CASE expression ranking the column's values, see OrderByCase
+type CaseOrder struct {
+	b      *Builder
+	column string
+	whens  []string
+	values []interface{}
+}
+
+// OrderByCase start ordering by buckets of values, e.g. OrderByCase("status").When("urgent", 0).Else(9)
+func (b *Builder) OrderByCase(column string) *CaseOrder {
+	if !isIdentifier(column) {
+		b.fail(fmt.Errorf("%w: column %q", ErrInvalidOrder, column))
+	}
+	return &CaseOrder{b: b, column: column}
+}
+
+// When rank rows where the column equals value
+func (c *CaseOrder) When(value interface{}, rank int) *CaseOrder {
+	c.whens = append(c.whens, fmt.Sprintf("WHEN ? THEN %d", rank))
+	c.values = append(c.values, value)
+	return c
+}
+
+// Else rank the remaining rows and append the CASE to the ORDER BY, after OrderBy
+func (c *CaseOrder) Else(rank int) *Builder {
+	return c.end(fmt.Sprintf(" ELSE %d", rank))
+}
+
+// End append the CASE to the ORDER BY, after OrderBy, the remaining rows rank NULL
+func (c *CaseOrder) End() *Builder {
+	return c.end("")
+}
+
+func (c *CaseOrder) end(elseClause string) *Builder {
+	if len(c.whens) == 0 {
+		c.b.fail(fmt.Errorf("%w: CASE on %q without WHEN", ErrInvalidOrder, c.column))
+		return c.b
+	}
+
+	var expr = fmt.Sprintf("CASE %s %s%s END", c.column, strings.Join(c.whens, " "), elseClause)
+	c.b.addSort(sortTerm{expr: expr}, c.values...)
+	return c.b
+}
@@ -0,0 +1,26 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderByCase(t *testing.T) {
+	var b = New(nil, "SELECT * FROM tickets").
+		Where("team_id = ?", 3).
+		OrderByCase("status").When("urgent", 0).When("open", 1).Else(9).
+		OrderByCol("created_at", Desc)
+
+	var queryString, _ = b.build()
+	if queryString != "SELECT * FROM tickets WHERE team_id = ? ORDER BY CASE status WHEN ? THEN 0 WHEN ? THEN 1 ELSE 9 END,created_at DESC" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if values := b.values(); len(values) != 3 || values[0] != 3 || values[1] != "urgent" || values[2] != "open" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	b = New(nil, "SELECT * FROM tickets").OrderByCase("status; --").When("open", 0).End()
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
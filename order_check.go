@@ -0,0 +1,44 @@
+package query
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnorderedPagination returned in strict mode when paginating without ORDER BY
+var ErrUnorderedPagination = errors.New("query: pagination without ORDER BY returns nondeterministic pages")
+
+// Logger receive the warnings of the builders, satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, args ...interface{}) {}
+
+var logger Logger = discardLogger{}
+
+// warned raw SQL already reported as unordered
+var unorderedWarnings sync.Map
+
+// SetLogger log the warnings of the builders, which are discarded by default
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// checkOrdered warn once per raw SQL when paginating without ORDER BY, or fail in strict mode
+func (b *Builder) checkOrdered() error {
+	if b.limit <= 0 || b.page <= 0 || b.buildOrderBy() != "" || indexTopLevelKeyword(b.RawSQLString, "ORDER") >= 0 {
+		return nil
+	}
+
+	if b.strict {
+		return ErrUnorderedPagination
+	}
+
+	if _, warned := unorderedWarnings.LoadOrStore(b.RawSQLString, true); !warned {
+		logger.Printf("paginating without ORDER BY returns nondeterministic pages: %s", b.RawSQLString)
+	}
+	return nil
+}
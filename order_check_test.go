@@ -0,0 +1,44 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testLogger []string
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+
+func TestCheckOrdered(t *testing.T) {
+	var logs testLogger
+	var previous = logger
+	SetLogger(&logs)
+	defer SetLogger(previous)
+
+	var unordered = "SELECT * FROM check_ordered"
+	for i := 0; i < 2; i++ {
+		if err := New(nil, unordered).Limit(10).Page(1).validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected one warning, got %v", logs)
+	}
+
+	if err := New(nil, unordered).Limit(10).Page(1).Strict().validate(); err != ErrUnorderedPagination {
+		t.Fatalf("expected ErrUnorderedPagination, got %v", err)
+	}
+
+	var ordered = []*Builder{
+		New(nil, unordered).Limit(10).Page(1).OrderBy("id"),
+		New(nil, "SELECT * FROM t ORDER BY id").Limit(10).Page(1).Strict(),
+		New(nil, "SELECT row_number() OVER (ORDER BY id) FROM t").Strict(),
+	}
+	for _, b := range ordered {
+		if err := b.validate(); err != nil {
+			t.Fatalf("%s: %v", b.RawSQLString, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderByCol(t *testing.T) {
+	var tests = []struct {
+		dialect string
+		want    string
+	}{
+		{DialectPostgres, "SELECT * FROM jobs ORDER BY ended_at DESC NULLS LAST,id ASC"},
+		{DialectMySQL, "SELECT * FROM jobs ORDER BY CASE WHEN ended_at IS NULL THEN 1 ELSE 0 END,ended_at DESC,id ASC"},
+	}
+
+	for _, test := range tests {
+		var b = New(nil, "SELECT * FROM jobs").
+			OrderByCol("ended_at", Desc, NullsLast).
+			OrderByCol("id", Asc).
+			WithDialect(test.dialect)
+		if queryString, _ := b.build(); queryString != test.want {
+			t.Errorf("got %s, want %s", queryString, test.want)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM jobs").OrderByCol("(SELECT 1)", Asc)
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+	if err := New(nil, "SELECT * FROM jobs").OrderByCol("id", "ASC; --").validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestOrderByColumns(t *testing.T) {
+	var b = New(nil, "SELECT * FROM posts").
+		AllowSort("created_at", "id").
+		OrderByColumns(Order{Col: "created_at", Dir: Desc}, Order{Col: "id", Dir: Asc})
+	if queryString, _ := b.build(); queryString != "SELECT * FROM posts ORDER BY created_at DESC,id ASC" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	b = New(nil, "SELECT * FROM posts").AllowSort("id").OrderByColumns(Order{Col: "password_hash", Dir: Asc})
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestOrderByCollate(t *testing.T) {
+	var tests = []struct {
+		dialect   string
+		collation string
+		want      string
+	}{
+		{DialectPostgres, "de-DE-x-icu", `SELECT * FROM users ORDER BY name COLLATE "de-DE-x-icu" ASC`},
+		{DialectMySQL, "utf8mb4_german2_ci", "SELECT * FROM users ORDER BY name COLLATE utf8mb4_german2_ci ASC"},
+	}
+
+	for _, test := range tests {
+		var b = New(nil, "SELECT * FROM users").
+			OrderByCollate("name", test.collation, Asc).
+			WithDialect(test.dialect)
+		if queryString, _ := b.build(); queryString != test.want {
+			t.Errorf("got %s, want %s", queryString, test.want)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM users").OrderByCollate("name", `C" DESC, (SELECT 1) --`, Asc)
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
+
+func TestOrderByInsensitive(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users").
+		AllowSort("name", "id").
+		OrderByInsensitive("name", Asc).
+		OrderByCol("id", Asc)
+	if queryString, _ := b.build(); queryString != "SELECT * FROM users ORDER BY LOWER(name) ASC,id ASC" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	b = New(nil, "SELECT * FROM users").AllowSort("id").OrderByInsensitive("email", Asc)
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package query
+
+import "errors"
+
+// ErrPageOutOfRange returned by PageOutOfRangeError builders when the page is past the last page
+var ErrPageOutOfRange = errors.New("query: page out of range")
+
+// PageOutOfRange behavior when the requested page is past the last page
+type PageOutOfRange int
+
+// Page out of range behaviors
+const (
+	// PageOutOfRangeKeep return the empty page paginated as any other, the default
+	PageOutOfRangeKeep PageOutOfRange = iota
+	// PageOutOfRangeEmpty return the empty page, PrevPage pointing to the last page
+	PageOutOfRangeEmpty
+	// PageOutOfRangeClamp return the last page instead, running the data query again
+	PageOutOfRangeClamp
+	// PageOutOfRangeError return ErrPageOutOfRange
+	PageOutOfRangeError
+)
+
+// WithPageOutOfRange set the behavior when the page is past the last page
+func (b *Builder) WithPageOutOfRange(behavior PageOutOfRange) *Builder {
+	b.outOfRange = behavior
+	return b
+}
+
+// pageInRange run paging and apply the out of range behavior
+func (b *Builder) pageInRange(paging func() (*Pagination, error)) (*Pagination, error) {
+	pagination, err := paging()
+	if err != nil || b.outOfRange == PageOutOfRangeKeep || pagination.total != nil {
+		return pagination, err
+	}
+
+	// an empty result still has page 1
+	var last = pagination.TotalPage
+	if last < 1 {
+		last = 1
+	}
+	if pagination.Page <= last {
+		return pagination, nil
+	}
+
+	switch b.outOfRange {
+	case PageOutOfRangeClamp:
+		b.page = last
+		return paging()
+	case PageOutOfRangeError:
+		return nil, ErrPageOutOfRange
+	default:
+		pagination.PrevPage = last
+		pagination.NextPage = pagination.Page
+		pagination.HasNext = false
+		if b.strict {
+			b.strictPages(pagination)
+		}
+		return pagination, nil
+	}
+}
@@ -0,0 +1,38 @@
+package query
+
+import "testing"
+
+func TestPaginateNoLimit(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users").Limit(0).Page(3)
+
+	queryString, _ := b.build()
+	if queryString != "SELECT * FROM users OFFSET 0" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	var pagination = b.paginate(5, nil)
+	if pagination.Page != 1 || pagination.PerPage != 5 || pagination.TotalPage != 1 || pagination.Offset != 0 || pagination.HasNext || pagination.HasPrev {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	if err := New(nil, "SELECT * FROM users").Limit(-1).validate(); err != ErrInvalidLimit {
+		t.Fatalf("expected ErrInvalidLimit, got %v", err)
+	}
+}
+
+func TestPaginateStrict(t *testing.T) {
+	var pagination = New(nil, "SELECT * FROM users").Limit(10).Page(1).Strict().paginate(0, nil)
+	if pagination.NextPage != 0 || pagination.PrevPage != 0 || pagination.TotalPage != 0 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	pagination = New(nil, "SELECT * FROM users").Limit(10).Page(2).Strict().paginate(25, nil)
+	if pagination.NextPage != 3 || pagination.PrevPage != 1 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	pagination = New(nil, "SELECT * FROM users").Limit(10).Page(1).paginate(0, nil)
+	if pagination.NextPage != 1 || pagination.PrevPage != 1 {
+		t.Fatalf("default pagination changed: %+v", pagination)
+	}
+}
@@ -0,0 +1,33 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSQL returned when the built statement doesn't parse
+var ErrInvalidSQL = errors.New("query: invalid SQL")
+
+// parseSQL validate a postgres statement with $n placeholders, set by SetSQLParser
+var parseSQL func(sql string) error
+
+// SetSQLParser parse the statements before execution, e.g. by importing the pgquery package
+func SetSQLParser(parse func(sql string) error) {
+	parseSQL = parse
+}
+
+// checkParse validate the built statements with the SQL parser, if any
+func (b *Builder) checkParse(statements ...statement) error {
+	if parseSQL == nil || b.dialect() != DialectPostgres {
+		return nil
+	}
+
+	for _, stmt := range statements {
+		sqlString, _ := Rebind(DialectPostgres, stmt.sql, stmt.values)
+		if err := parseSQL(sqlString); err != nil {
+			return fmt.Errorf("%w: %v: %s", ErrInvalidSQL, err, sqlString)
+		}
+	}
+
+	return nil
+}
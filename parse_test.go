@@ -0,0 +1,41 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckParse(t *testing.T) {
+	var previous = parseSQL
+	defer func() {
+		parseSQL = previous
+	}()
+
+	var parsed []string
+	parseSQL = func(sql string) error {
+		parsed = append(parsed, sql)
+		if strings.Contains(sql, "LIMIT 10 UNION") {
+			return errors.New("syntax error at or near UNION")
+		}
+		return nil
+	}
+
+	var b = New(nil, "SELECT * FROM users").WithDialect(DialectPostgres).Where("id = ?", 1)
+	sqlString, values := b.ToSQL()
+	if err := b.checkParse(statement{sqlString, values}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed[0] != "SELECT * FROM users WHERE id = $1" {
+		t.Fatalf("unexpected parsed statements: %v", parsed)
+	}
+
+	var err = b.checkParse(statement{"SELECT 1 LIMIT 10 UNION SELECT 2", nil})
+	if !errors.Is(err, ErrInvalidSQL) {
+		t.Fatalf("expected ErrInvalidSQL, got %v", err)
+	}
+
+	if err = New(nil, "SELECT 1").WithDialect(DialectMySQL).checkParse(statement{"SELECT 1 LIMIT 10 UNION SELECT 2", nil}); err != nil {
+		t.Fatalf("only postgres statements are parsed: %v", err)
+	}
+}
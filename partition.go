@@ -0,0 +1,115 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPartitionKeyMissing returned when a partition key has no filter
+var ErrPartitionKeyMissing = errors.New("query: partition key not filtered")
+
+// WithPartitionKey declare the partition keys, queries must filter each of them
+func (b *Builder) WithPartitionKey(columns ...string) *Builder {
+	b.partitionKeys = append(b.partitionKeys, columns...)
+	for _, r := range b.dateRanges {
+		for _, col := range columns {
+			b.whereRange(col, r.from, r.to)
+		}
+	}
+	return b
+}
+
+type dateRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// WhereDateRange filter column to [from, to), also applied to the partition keys
+func (b *Builder) WhereDateRange(column string, from time.Time, to time.Time) *Builder {
+	if from.IsZero() && to.IsZero() {
+		return b
+	}
+
+	b.dateRanges = append(b.dateRanges, dateRange{from: from, to: to})
+	b.whereRange(column, from, to)
+	for _, key := range b.partitionKeys {
+		b.whereRange(key, from, to)
+	}
+
+	return b
+}
+
+// whereRange filter col to [from, to) and record it as filtered, once per column and range
+func (b *Builder) whereRange(col string, from time.Time, to time.Time) {
+	for _, filtered := range b.filteredKeys {
+		if filtered.column == col && filtered.from.Equal(from) && filtered.to.Equal(to) {
+			return
+		}
+	}
+
+	if !from.IsZero() {
+		b.Where(fmt.Sprintf("%s >= ?", col), from)
+	}
+	if !to.IsZero() {
+		b.Where(fmt.Sprintf("%s < ?", col), to)
+	}
+	b.filteredKeys = append(b.filteredKeys, filteredKey{column: col, dateRange: dateRange{from: from, to: to}})
+}
+
+type filteredKey struct {
+	column string
+	dateRange
+}
+
+// checkPartitionKeys ensure each partition key is filtered
+func (b *Builder) checkPartitionKeys() error {
+	if len(b.partitionKeys) == 0 {
+		return nil
+	}
+
+	var columns = map[string]bool{}
+	for _, filtered := range b.filteredKeys {
+		columns[filtered.column] = true
+	}
+	for _, where := range b.wheres {
+		for _, col := range conditionColumns(where) {
+			columns[col] = true
+		}
+	}
+
+	for _, key := range b.partitionKeys {
+		if !columns[key] {
+			return fmt.Errorf("%w: %s", ErrPartitionKeyMissing, key)
+		}
+	}
+
+	return nil
+}
+
+// conditionColumns identifiers of a condition outside of string literals, e.g. o.created_at
+func conditionColumns(condition string) []string {
+	var columns []string
+	for i := 0; i < len(condition); i++ {
+		var c = condition[i]
+		switch {
+		case c == '\'':
+			for i++; i < len(condition) && condition[i] != '\''; i++ {
+			}
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			var start = i
+			for i < len(condition) && (condition[i] == '_' || condition[i] == '.' || condition[i] >= 'a' && condition[i] <= 'z' ||
+				condition[i] >= 'A' && condition[i] <= 'Z' || condition[i] >= '0' && condition[i] <= '9') {
+				i++
+			}
+			columns = append(columns, condition[start:i])
+			i--
+		case c >= '0' && c <= '9':
+			for i < len(condition) && (condition[i] >= '0' && condition[i] <= '9' || condition[i] == '.') {
+				i++
+			}
+			i--
+		}
+	}
+	return columns
+}
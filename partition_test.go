@@ -0,0 +1,63 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWhereDateRangePartitionKey(t *testing.T) {
+	var from = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	var to = from.AddDate(0, 1, 0)
+
+	var b = New(nil, "SELECT o.* FROM orders o JOIN order_items oi ON oi.order_id = o.id").
+		WithPartitionKey("o.created_at", "oi.created_at").
+		WhereDateRange("o.created_at", from, to)
+
+	queryString, _ := b.build()
+	var want = "SELECT o.* FROM orders o JOIN order_items oi ON oi.order_id = o.id WHERE o.created_at >= ? AND o.created_at < ? AND oi.created_at >= ? AND oi.created_at < ?"
+	if queryString != want {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if err := b.checkPartitionKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = New(nil, "SELECT o.* FROM orders o").
+		WithPartitionKey("o.created_at").
+		Where("o.status = ?", "paid")
+	if err := b.checkPartitionKeys(); !errors.Is(err, ErrPartitionKeyMissing) {
+		t.Fatalf("expected ErrPartitionKeyMissing, got %v", err)
+	}
+}
+
+func TestPartitionKeyOrder(t *testing.T) {
+	var from = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var b = New(nil, "SELECT o.* FROM orders o JOIN order_items oi ON oi.order_id = o.id").
+		WhereDateRange("o.created_at", from, time.Time{}).
+		WithPartitionKey("o.created_at", "oi.created_at")
+
+	queryString, _ := b.build()
+	var want = "SELECT o.* FROM orders o JOIN order_items oi ON oi.order_id = o.id WHERE o.created_at >= ? AND oi.created_at >= ?"
+	if queryString != want || len(b.values()) != 2 {
+		t.Fatalf("unexpected query: %s %v", queryString, b.values())
+	}
+	if err := b.checkPartitionKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = New(nil, "SELECT o.* FROM orders o").
+		WithPartitionKey("o.created_at").
+		Where("o.created_at_local > ? AND note = 'o.created_at'", from)
+	if err := b.checkPartitionKeys(); !errors.Is(err, ErrPartitionKeyMissing) {
+		t.Fatalf("expected ErrPartitionKeyMissing, got %v", err)
+	}
+
+	b = New(nil, "SELECT o.* FROM orders o").
+		WithPartitionKey("o.created_at").
+		Where("o.created_at > ?", from)
+	if err := b.checkPartitionKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/thaitanloi365/go-query/pgquery
+
+go 1.18
+
+require (
+	github.com/pganalyze/pg_query_go/v2 v2.2.0
+	github.com/thaitanloi365/go-query v0.0.0
+)
+
+replace github.com/thaitanloi365/go-query => ../
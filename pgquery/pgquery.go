@@ -0,0 +1,25 @@
+// Package pgquery parse the statements of the builders with the postgres parser, requires cgo
+package pgquery
+
+import (
+	"fmt"
+
+	pg_query "github.com/pganalyze/pg_query_go/v2"
+	query "github.com/thaitanloi365/go-query"
+)
+
+func init() {
+	query.SetSQLParser(Parse)
+}
+
+// Parse validate a single postgres statement
+func Parse(sql string) error {
+	result, err := pg_query.Parse(sql)
+	if err != nil {
+		return err
+	}
+	if len(result.Stmts) != 1 {
+		return fmt.Errorf("expected 1 statement, got %d", len(result.Stmts))
+	}
+	return nil
+}
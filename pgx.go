@@ -0,0 +1,155 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrPgxNotSupported returned by the pgx paths for builder options they can't honour
+var ErrPgxNotSupported = errors.New("query: option not supported on pgx")
+
+// PgxConn native pgx handle, satisfied by *pgx.Conn, *pgxpool.Pool and pgx.Tx
+type PgxConn interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// PgxRowsFunc read the rows of the data query, rows are closed by the caller
+type PgxRowsFunc = func(rows pgx.Rows) (interface{}, error)
+
+// PgxRows run the data query on pgx, close the rows to release the slot
+func (b *Builder) PgxRows(ctx context.Context, conn PgxConn) (pgx.Rows, error) {
+	sqlString, _ := b.build()
+	var values = b.values()
+
+	if err := b.beforePgx(ctx, statement{sqlString, values}); err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlString, values = Rebind(DialectPostgres, sqlString, values)
+
+	rows, err := conn.Query(ctx, sqlString, values...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return &pgxRows{Rows: rows, release: release}, nil
+}
+
+// pgxRows release the concurrency slot once the rows are closed
+type pgxRows struct {
+	pgx.Rows
+	release func()
+	once    sync.Once
+}
+
+func (r *pgxRows) Close() {
+	r.Rows.Close()
+	r.once.Do(r.release)
+}
+
+// PagingPgx paging on pgx, the count and data queries are sent in one batch
+func (b *Builder) PagingPgx(ctx context.Context, conn PgxConn, f PgxRowsFunc) (*Pagination, error) {
+	if b.page < 1 {
+		b.page = 1
+	}
+
+	return b.pageInRange(func() (*Pagination, error) {
+		return b.pagingPgx(ctx, conn, f)
+	})
+}
+
+// pagingPgx paging of the current page on pgx
+func (b *Builder) pagingPgx(ctx context.Context, conn PgxConn, f PgxRowsFunc) (*Pagination, error) {
+	sqlString, countSQLString := b.build()
+	values, countValues := b.boundValues()
+
+	if err := b.beforePgx(ctx, statement{sqlString, values}, statement{countSQLString, countValues}); err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sqlString, values = Rebind(DialectPostgres, sqlString, values)
+	countSQLString, countValues = Rebind(DialectPostgres, countSQLString, countValues)
+
+	var batch = &pgx.Batch{}
+	batch.Queue(countSQLString, countValues...)
+	batch.Queue(sqlString, values...)
+
+	var results = conn.SendBatch(ctx, batch)
+	defer results.Close()
+
+	var count int
+	if err := results.QueryRow().Scan(&count); err != nil {
+		return nil, err
+	}
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := f(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var pagination = b.paginate(count, result)
+	b.attachWindow(pagination)
+	return pagination, nil
+}
+
+// beforePgx pre-execution checks of the pgx paths, failing on the options needing an Executor or gorm
+func (b *Builder) beforePgx(ctx context.Context, statements ...statement) error {
+	schema, err := b.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	var options = []struct {
+		set  bool
+		name string
+	}{
+		{b.cache != nil, "Cached"},
+		{b.degraded > 0, "Degraded"},
+		{len(b.facets) > 0, "WithFacets"},
+		{b.summary != "", "WithSummary"},
+		{b.lazyCount, "WithLazyCount"},
+		{b.maxCost > 0 || b.maxRows > 0, "WithMaxCost"},
+		{b.mview != nil && b.mview.policy.MaxAge > 0, "WithMaterializedView refresh"},
+		{schema != "", "WithSchema"},
+		{b.readOnlyTx, "ReadOnlyTx"},
+		{b.flight != "", "Singleflight"},
+		{b.prefetch, "Prefetch"},
+		{b.roundTrip, "SingleRoundTrip"},
+	}
+	for _, option := range options {
+		if option.set {
+			return fmt.Errorf("%w: %s", ErrPgxNotSupported, option.name)
+		}
+	}
+
+	return b.beforeExec(ctx, statements...)
+}
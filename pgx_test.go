@@ -0,0 +1,27 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPgxUnsupportedOptions(t *testing.T) {
+	var tests = []*Builder{
+		New(nil, "SELECT * FROM users").OrderBy("id").Cached(time.Minute),
+		New(nil, "SELECT * FROM users").OrderBy("id").WithLazyCount(),
+		New(nil, "SELECT * FROM users").OrderBy("id").WithSummary("SUM(amount) AS total"),
+		New(nil, "SELECT * FROM users").OrderBy("id").WithSchema("tenant_a"),
+	}
+
+	for _, b := range tests {
+		if _, err := b.PagingPgx(context.Background(), nil, nil); !errors.Is(err, ErrPgxNotSupported) {
+			t.Fatalf("expected ErrPgxNotSupported, got %v", err)
+		}
+	}
+
+	if _, err := New(nil, "SELECT * FROM users; DROP TABLE users").PgxRows(context.Background(), nil); !errors.Is(err, ErrStackedStatements) {
+		t.Fatalf("expected ErrStackedStatements, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"time"
+)
+
+// maxPrefetches prefetches running at once, beyond it pages aren't prefetched
+const maxPrefetches = 16
+
+var prefetches = make(chan struct{}, maxPrefetches)
+
+// Prefetch cache the next page in the background after serving a page, see Cached
+func (b *Builder) Prefetch() *Builder {
+	b.prefetch = true
+	return b
+}
+
+// nextPage copy of the builder for the page after pagination, nil when there is nothing to prefetch
+func (b *Builder) nextPage(pagination *Pagination) *Builder {
+	if !b.prefetch || b.cache == nil || pagination == nil || !pagination.HasNext {
+		return nil
+	}
+
+	var next = b.clone()
+	next.page = pagination.Page + 1
+	next.prefetch = false
+	next.failFast = true
+	return next
+}
+
+// clone copy of the builder sharing nothing the caller can still modify
+func (b *Builder) clone() *Builder {
+	var c = *b
+	c.wheres = append([]string(nil), b.wheres...)
+	c.whereValues = append([]interface{}(nil), b.whereValues...)
+	c.joins = append([]string(nil), b.joins...)
+	c.joinValues = append([]interface{}(nil), b.joinValues...)
+	c.ctes = append([]cte(nil), b.ctes...)
+	c.selects = append([]string(nil), b.selects...)
+	c.selectValues = append([]interface{}(nil), b.selectValues...)
+	c.rankOrderBy = append([]string(nil), b.rankOrderBy...)
+	c.orderValues = append([]interface{}(nil), b.orderValues...)
+	c.partitionKeys = append([]string(nil), b.partitionKeys...)
+	c.filteredKeys = append([]filteredKey(nil), b.filteredKeys...)
+	c.dateRanges = append([]dateRange(nil), b.dateRanges...)
+	c.indexes = append([]string(nil), b.indexes...)
+	c.tainted = append([]string(nil), b.tainted...)
+	c.sorts = append([]sortTerm(nil), b.sorts...)
+	c.sortValues = append([]interface{}(nil), b.sortValues...)
+	c.facets = append([]string(nil), b.facets...)
+
+	c.namedWhereValues = make(map[string]interface{}, len(b.namedWhereValues))
+	for k, v := range b.namedWhereValues {
+		c.namedWhereValues[k] = v
+	}
+	if b.sortable != nil {
+		c.sortable = make(map[string]bool, len(b.sortable))
+		for k, v := range b.sortable {
+			c.sortable[k] = v
+		}
+	}
+	if b.cache != nil {
+		var cache = *b.cache
+		c.cache = &cache
+	}
+	if b.window != nil {
+		var window = *b.window
+		c.window = &window
+	}
+	return &c
+}
+
+// startPrefetch run prefetch in the background unless too many prefetches already run
+func startPrefetch(prefetch func()) {
+	select {
+	case prefetches <- struct{}{}:
+	default:
+		return
+	}
+
+	go func() {
+		defer func() { <-prefetches }()
+		prefetch()
+	}()
+}
+
+// prefetchRows prefetch the next page of PagingRows, keeping the context values but not its cancellation
+func (b *Builder) prefetchRows(ctx context.Context, pagination *Pagination, f RowsFunc) {
+	if next := b.nextPage(pagination); next != nil {
+		startPrefetch(func() {
+			next.PagingRows(detachedContext{ctx}, f)
+		})
+	}
+}
+
+// prefetchFunc prefetch the next page of PagingFuncE
+func (b *Builder) prefetchFunc(pagination *Pagination, f ExecFunc) {
+	if next := b.nextPage(pagination); next != nil {
+		startPrefetch(func() {
+			next.PagingFuncE(f)
+		})
+	}
+}
+
+// detachedContext context values of the request, e.g. the tenant, without its deadline
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,17 @@
+package query
+
+import "testing"
+
+func TestNextPageCopy(t *testing.T) {
+	var b = New(nil, "SELECT id FROM users").Where("status = ?", "active").Limit(10).Cached(0).Prefetch()
+
+	var next = b.nextPage(&Pagination{Page: 1, HasNext: true})
+	if next == nil || next.page != 2 || next.prefetch || !next.failFast {
+		t.Fatalf("unexpected next page: %+v", next)
+	}
+
+	b.Where("team_id = ?", 3).WhereNamed("name", "x")
+	if len(next.wheres) != 1 || len(next.whereValues) != 1 || len(next.namedWhereValues) != 0 {
+		t.Fatalf("next page should not see later conditions: %v %v", next.wheres, next.namedWhereValues)
+	}
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// WithPreparedStatement bind LIMIT and OFFSET as parameters, so every page shares a statement
+func (b *Builder) WithPreparedStatement() *Builder {
+	b.prepared = true
+	return b
+}
+
+// PrepareStmt gorm handle caching prepared statements by SQL, keep and reuse the returned handle
+func PrepareStmt(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{PrepareStmt: true})
+}
+
+// WithStatementCache reuse at most size prepared statements on a *sql.DB
+func (e *SQLExecutor) WithStatementCache(size int) *SQLExecutor {
+	e.stmts = &stmtCache{
+		size:  size,
+		stmts: map[string]*sql.Stmt{},
+	}
+	return e
+}
+
+// Close close the cached prepared statements
+func (e *SQLExecutor) Close() error {
+	return e.stmts.close()
+}
+
+type stmtCache struct {
+	mutex sync.Mutex
+	size  int
+	stmts map[string]*sql.Stmt
+}
+
+// get cached statement for query, preparing it when there is room, nil to run unprepared
+func (c *stmtCache) get(ctx context.Context, conn SQLConn, query string) *sql.Stmt {
+	if c == nil {
+		return nil
+	}
+
+	db, ok := conn.(*sql.DB)
+	if !ok {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt
+	}
+	if len(c.stmts) >= c.size {
+		return nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil
+	}
+	c.stmts[query] = stmt
+	return stmt
+}
+
+func (c *stmtCache) close() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,23 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestPreparedStatementBindsPaging(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users").
+		Where("id > ?", 5).
+		Limit(10).
+		Page(3).
+		WithPreparedStatement()
+
+	queryString, _ := b.build()
+	if queryString != "SELECT * FROM users WHERE id > ? LIMIT ? OFFSET ?" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	var values = b.values()
+	if len(values) != 3 || values[1] != 10 || values[2] != 20 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
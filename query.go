@@ -1,11 +1,16 @@
 package query
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -17,7 +22,7 @@ type DB interface {
 	Clauses(conds ...clause.Expression) *gorm.DB
 	Table(name string, args ...interface{}) *gorm.DB
 	Distinct(args ...interface{}) *gorm.DB
-	Select(query interface{}, args ...interface{})
+	Select(query interface{}, args ...interface{}) *gorm.DB
 	Omit(columns ...string) *gorm.DB
 	Where(query interface{}, args ...interface{}) *gorm.DB
 	Not(query interface{}, args ...interface{}) *gorm.DB
@@ -29,6 +34,8 @@ type DB interface {
 	Limit(limit int) *gorm.DB
 	Offset(offset int) *gorm.DB
 	Raw(sql string, values ...interface{}) *gorm.DB
+	Exec(sql string, values ...interface{}) *gorm.DB
+	WithContext(ctx context.Context) *gorm.DB
 	Unscoped() *gorm.DB
 	Assign(attrs ...interface{}) *gorm.DB
 	Attrs(attrs ...interface{}) *gorm.DB
@@ -36,6 +43,20 @@ type DB interface {
 	Row() *sql.Row
 	Rows() (*sql.Rows, error)
 	Scan(dest interface{}) *gorm.DB
+	GetGorm() *gorm.DB
+}
+
+type gormDB struct {
+	*gorm.DB
+}
+
+// WrapGorm wrap a gorm DB as DB
+func WrapGorm(db *gorm.DB) DB {
+	return &gormDB{db}
+}
+
+func (db *gormDB) GetGorm() *gorm.DB {
+	return db.DB
 }
 
 // ExecFunc exec func
@@ -46,17 +67,22 @@ type WhereFunc = func(builder *Builder)
 
 // Pagination ...
 type Pagination struct {
-	HasNext     bool        `json:"has_next"`
-	HasPrev     bool        `json:"has_prev"`
-	PerPage     int         `json:"per_page"`
-	NextPage    int         `json:"next_page"`
-	Page        int         `json:"current_page"`
-	PrevPage    int         `json:"prev_page"`
-	Offset      int         `json:"offset"`
-	Records     interface{} `json:"records"`
-	TotalRecord int         `json:"total_record"`
-	TotalPage   int         `json:"total_page"`
-	Metadata    interface{} `json:"metadata"`
+	HasNext     bool                   `json:"has_next"`
+	HasPrev     bool                   `json:"has_prev"`
+	PerPage     int                    `json:"per_page"`
+	NextPage    int                    `json:"next_page"`
+	Page        int                    `json:"current_page"`
+	PrevPage    int                    `json:"prev_page"`
+	Offset      int                    `json:"offset"`
+	Records     interface{}            `json:"records"`
+	TotalRecord int                    `json:"total_record"`
+	TotalPage   int                    `json:"total_page"`
+	Metadata    interface{}            `json:"metadata"`
+	Summary     map[string]interface{} `json:"summary,omitempty"`
+	Window      *TimeWindow            `json:"window,omitempty"`
+	Streamed    bool                   `json:"streamed,omitempty"`
+	Err         error                  `json:"-"`
+	total       *lazyTotal
 }
 
 // Builder query config
@@ -65,12 +91,59 @@ type Builder struct {
 	RawSQLString     string
 	limit            int
 	page             int
-	hasWhere         bool
+	wheres           []string
 	whereValues      []interface{}
+	joins            []string
+	joinValues       []interface{}
 	namedWhereValues map[string]interface{}
 	orderBy          string
 	groupBy          string
 	wrapJSON         bool
+	ctes             []cte
+	dialectName      string
+	maxCost          float64
+	maxRows          float64
+	mview            *materializedView
+	sample           *sample
+	grouping         *grouping
+	selects          []string
+	selectValues     []interface{}
+	search           *textSearch
+	similar          *similarity
+	rankOrderBy      []string
+	orderValues      []interface{}
+	partitionKeys    []string
+	filteredKeys     []filteredKey
+	dateRanges       []dateRange
+	exec             Executor
+	replica          int
+	schema           string
+	prepared         bool
+	maxConcurrent    int
+	rateLimit        RateLimiter
+	failFast         bool
+	lazyCount        bool
+	streamRows       int
+	streamBytes      int
+	cache            *resultCache
+	indexes          []string
+	outOfRange       PageOutOfRange
+	strict           bool
+	readOnly         bool
+	readOnlyTx       bool
+	tainted          []string
+	sorts            []sortTerm
+	sortValues       []interface{}
+	sortable         map[string]bool
+	facets           []string
+	summary          string
+	flight           string
+	prefetch         bool
+	window           *timeWindow
+	roundTrip        bool
+	degraded         time.Duration
+	listener         *Listener
+	err              error
 }
 
 // New init
@@ -80,7 +153,6 @@ func New(db DB, rawSQL string) *Builder {
 		RawSQLString:     rawSQL,
 		whereValues:      []interface{}{},
 		namedWhereValues: map[string]interface{}{},
-		hasWhere:         false,
 		orderBy:          "",
 		groupBy:          "",
 		wrapJSON:         false,
@@ -88,6 +160,35 @@ func New(db DB, rawSQL string) *Builder {
 	return builder
 }
 
+// Constructor errors returned by NewE
+var (
+	ErrNilDB      = errors.New("query: nil db")
+	ErrEmptySQL   = errors.New("query: empty raw SQL")
+	ErrLimitInSQL = errors.New("query: raw SQL already has LIMIT or OFFSET, use Limit and Page")
+)
+
+// NewE init like New, validating the db and the raw SQL up front
+func NewE(db DB, rawSQL string) (*Builder, error) {
+	if db == nil {
+		return nil, ErrNilDB
+	}
+	if v := reflect.ValueOf(db); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, ErrNilDB
+	}
+
+	if strings.TrimSpace(rawSQL) == "" {
+		return nil, ErrEmptySQL
+	}
+
+	for _, keyword := range []string{"LIMIT", "OFFSET", "FETCH"} {
+		if indexTopLevelKeyword(rawSQL, keyword) >= 0 {
+			return nil, ErrLimitInSQL
+		}
+	}
+
+	return New(db, rawSQL), nil
+}
+
 // WithWrapJSON wrap json
 func (b *Builder) WithWrapJSON(isWrapJSON bool) *Builder {
 	b.wrapJSON = isWrapJSON
@@ -102,6 +203,7 @@ func (b *Builder) count(countSQL DB, done chan bool, count *int) {
 
 // WhereNamed where
 func (b *Builder) WhereNamed(key string, value interface{}) *Builder {
+	b.taint(value, "WhereNamed "+key)
 	b.namedWhereValues[key] = value
 	return b
 }
@@ -112,15 +214,34 @@ func (b *Builder) Where(query interface{}, args ...interface{}) *Builder {
 		b.whereValues = append(b.whereValues, args...)
 	}
 
-	if b.hasWhere {
-		b.RawSQLString = fmt.Sprintf("%s AND %v", b.RawSQLString, query)
+	if s, ok := query.(string); ok {
+		b.wheres = append(b.wheres, s)
 	} else {
-		b.RawSQLString = fmt.Sprintf("%s WHERE %v", b.RawSQLString, query)
-		b.hasWhere = true
+		b.taint(query, "Where")
+		b.wheres = append(b.wheres, fmt.Sprint(query))
 	}
 	return b
 }
 
+// writeWhere write the WHERE clause of the conditions
+func (b *Builder) writeWhere(sb *strings.Builder) {
+	for i, where := range b.wheres {
+		if i == 0 {
+			sb.WriteString(" WHERE ")
+		} else {
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(where)
+	}
+}
+
+// Joins add a join clause, placed before the WHERE conditions
+func (b *Builder) Joins(query string, args ...interface{}) *Builder {
+	b.joins = append(b.joins, query)
+	b.joinValues = append(b.joinValues, args...)
+	return b
+}
+
 // OrderBy specify order when retrieve records from database
 func (b *Builder) OrderBy(orderBy ...string) *Builder {
 	if len(orderBy) > 0 {
@@ -129,6 +250,26 @@ func (b *Builder) OrderBy(orderBy ...string) *Builder {
 	return b
 }
 
+// buildOrderBy order by clause, rank orders (search rank, similarity) come first
+func (b *Builder) buildOrderBy() string {
+	if len(b.rankOrderBy) == 0 && len(b.sorts) == 0 && b.window == nil {
+		return b.orderBy
+	}
+
+	var orderBy = append([]string{}, b.rankOrderBy...)
+	if b.orderBy != "" {
+		orderBy = append(orderBy, b.orderBy)
+	}
+	for _, sort := range b.sorts {
+		orderBy = append(orderBy, sort.build(b.dialect()))
+	}
+	if b.window != nil {
+		orderBy = append(orderBy, b.window.order())
+	}
+
+	return strings.Join(orderBy, ",")
+}
+
 // GroupBy specify the group method on the find
 func (b *Builder) GroupBy(groupBy string) *Builder {
 	b.groupBy = groupBy
@@ -141,7 +282,10 @@ func (b *Builder) WhereFunc(f WhereFunc) *Builder {
 	return b
 }
 
-// Limit limit
+// ErrInvalidLimit returned when executing a builder with a negative limit
+var ErrInvalidLimit = errors.New("query: limit must not be negative")
+
+// Limit limit, 0 for no limit: every record is on page 1 and other pages are ignored
 func (b *Builder) Limit(limit int) *Builder {
 	b.limit = limit
 	return b
@@ -153,88 +297,403 @@ func (b *Builder) Page(page int) *Builder {
 	return b
 }
 
+// values bound values in the order they appear in the built query
+func (b *Builder) values() []interface{} {
+	values, _ := b.boundValues()
+	return values
+}
+
+// boundValues values of the data and count queries in a single allocation
+func (b *Builder) boundValues() (values []interface{}, countValues []interface{}) {
+	values = b.appendCountValues(make([]interface{}, 0, b.valuesLen()))
+	countValues = values[:len(values):len(values)]
+
+	values = append(values, b.orderValues...)
+	values = append(values, b.sortValues...)
+	unwrapUserInput(values)
+	if b.prepared && b.limit > 0 {
+		values = append(values, b.limit)
+	}
+	if b.prepared && b.page > 0 {
+		values = append(values, b.offset())
+	}
+
+	return values, countValues
+}
+
+// valuesLen upper bound of the number of bound values
+func (b *Builder) valuesLen() int {
+	var n = len(b.selectValues) + len(b.joinValues) + len(b.whereValues) + len(b.orderValues) + len(b.sortValues) + 2
+	for _, c := range b.ctes {
+		switch {
+		case c.expr != nil:
+			n += len(c.expr.Vars)
+		case c.recursive:
+			n += c.base.valuesLen() + c.step.valuesLen()
+		default:
+			n += c.base.valuesLen()
+		}
+	}
+
+	return n
+}
+
+// offset of the current page
+func (b *Builder) offset() int {
+	if b.page > 1 && b.limit > 0 {
+		return (b.page - 1) * b.limit
+	}
+	return 0
+}
+
+// countValues bound values of the count query, which has no ORDER BY
+func (b *Builder) countValues() []interface{} {
+	_, countValues := b.boundValues()
+	return countValues
+}
+
+// appendCountValues append the values of the count query to values
+func (b *Builder) appendCountValues(values []interface{}) []interface{} {
+	values = b.appendWithValues(values)
+	values = append(values, b.selectValues...)
+	values = append(values, b.joinValues...)
+	return append(values, b.whereValues...)
+}
+
 // Build build
 func (b *Builder) build() (queryString string, countQuery string) {
-	var rawSQLString = b.RawSQLString
-	for key, value := range b.namedWhereValues {
-		switch v := value.(type) {
+	var body = b.buildBody()
+	with, _ := b.buildWith()
+
+	var hint = b.hintComment()
+
+	var sb strings.Builder
+	sb.Grow(len(hint) + len(with) + len(body) + 64)
+	sb.WriteString(hint)
+	if b.wrapJSON {
+		sb.WriteString("\n\t\t")
+		if with != "" {
+			sb.WriteString(with)
+			sb.WriteString(",")
+		} else {
+			sb.WriteString("WITH")
+		}
+		sb.WriteString(" alias AS (")
+	} else if with != "" {
+		sb.WriteString(with)
+		sb.WriteByte(' ')
+	}
+
+	sb.WriteString(body)
+
+	if orderBy := b.buildOrderBy(); orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(orderBy)
+	}
+
+	if b.limit > 0 {
+		sb.WriteString(" LIMIT ")
+		if b.prepared {
+			sb.WriteByte('?')
+		} else {
+			sb.WriteString(strconv.Itoa(b.limit))
+		}
+	}
+
+	if b.page > 0 {
+		sb.WriteString(" OFFSET ")
+		if b.prepared {
+			sb.WriteByte('?')
+		} else {
+			sb.WriteString(strconv.Itoa(b.offset()))
+		}
+	}
+
+	if b.wrapJSON {
+		sb.WriteString(")\n\t\tSELECT to_jsonb(row_to_json(alias)) AS alias FROM alias\n\t\t")
+	}
+	queryString = sb.String()
+
+	countQuery = hint + wrapQuery(with, body, "COUNT(1)")
+
+	return
+}
+
+// wrapQuery select columns over the query body as subquery t, e.g. the count query
+func wrapQuery(with string, body string, columns string) string {
+	var sb strings.Builder
+	sb.Grow(len(with) + len(body) + len(columns) + 24)
+	if with != "" {
+		sb.WriteString(with)
+		sb.WriteByte(' ')
+	}
+	sb.WriteString("SELECT ")
+	sb.WriteString(columns)
+	sb.WriteString(" FROM (")
+	sb.WriteString(body)
+	sb.WriteString(") t")
+	return sb.String()
+}
+
+// buildBody build the query shared by the data and count queries: raw SQL, joins, conditions and GROUP BY
+func (b *Builder) buildBody() string {
+	var sb strings.Builder
+	sb.WriteString(b.applySample(b.applyIndexHint(b.applySelects(b.RawSQLString))))
+	for _, join := range b.joins {
+		sb.WriteByte(' ')
+		sb.WriteString(join)
+	}
+	b.writeWhere(&sb)
+
+	var body = sb.String()
+	if len(b.namedWhereValues) > 0 {
+		body = b.namedReplacer().Replace(body)
+	}
+
+	if groupBy := b.buildGroupBy(); groupBy != "" {
+		body = body + " GROUP BY " + groupBy
+	}
+
+	return body
+}
+
+// namedReplacer replacer of the @key named values, longer keys first so @id doesn't match @id2
+func (b *Builder) namedReplacer() *strings.Replacer {
+	var keys = make([]string, 0, len(b.namedWhereValues))
+	for key := range b.namedWhereValues {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+
+	var pairs = make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		var replacement string
+		switch v := b.namedWhereValues[key].(type) {
 		case string:
-			rawSQLString = strings.ReplaceAll(rawSQLString, fmt.Sprintf("@%s", key), fmt.Sprintf("'%v'", value))
+			replacement = fmt.Sprintf("'%v'", v)
 		case []string:
 			var cols = []string{}
 			for _, str := range v {
 				cols = append(cols, fmt.Sprintf("'%s'", str))
 			}
-			rawSQLString = strings.ReplaceAll(rawSQLString, fmt.Sprintf("@%s", key), fmt.Sprintf("%v", strings.Join(cols, ",")))
+			replacement = strings.Join(cols, ",")
 		default:
-			rawSQLString = strings.ReplaceAll(rawSQLString, fmt.Sprintf("@%s", key), fmt.Sprintf("%v", value))
+			replacement = fmt.Sprintf("%v", v)
 		}
+		pairs = append(pairs, "@"+key, replacement)
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+// ToSQL built data query and its bound values, with ? placeholders
+func (b *Builder) ToSQL() (string, []interface{}) {
+	queryString, _ := b.build()
+	return queryString, b.values()
+}
+
+// ToCountSQL built count query and its bound values, with ? placeholders
+func (b *Builder) ToCountSQL() (string, []interface{}) {
+	_, countQuery := b.build()
+	return countQuery, b.countValues()
+}
+
+// statement built SQL with its bound values
+type statement struct {
+	sql    string
+	values []interface{}
+}
 
+// validate run the checks which don't need the database
+func (b *Builder) validate() error {
+	if b.err != nil {
+		return b.err
 	}
 
-	queryString = rawSQLString
-	countQuery = rawSQLString
-	if b.groupBy != "" {
-		queryString = fmt.Sprintf("%s GROUP BY %s", queryString, b.groupBy)
-		countQuery = queryString
+	if b.limit < 0 {
+		return ErrInvalidLimit
 	}
 
-	if b.orderBy != "" {
-		queryString = fmt.Sprintf("%s ORDER BY %s", queryString, b.orderBy)
+	if err := b.checkOrdered(); err != nil {
+		return err
 	}
 
-	if b.limit > 0 {
-		queryString = fmt.Sprintf("%s LIMIT %d", queryString, b.limit)
+	if err := b.checkTaint(); err != nil {
+		return err
 	}
 
-	if b.page > 0 {
-		var offset = 0
-		if b.page > 1 {
-			offset = (b.page - 1) * b.limit
-		}
+	if err := b.checkRandom(); err != nil {
+		return err
+	}
+
+	return b.checkPartitionKeys()
+}
+
+// beforeExec run the pre-execution checks for the given statements
+func (b *Builder) beforeExec(ctx context.Context, statements ...statement) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
 
-		queryString = fmt.Sprintf("%s OFFSET %d", queryString, offset)
+	if err := b.checkStatements(statements...); err != nil {
+		return err
 	}
 
-	if b.wrapJSON {
-		queryString = fmt.Sprintf(`
-		WITH alias AS (%s)
-		SELECT to_jsonb(row_to_json(alias)) AS alias FROM alias
-		`, queryString)
+	if err := b.refreshIfStale(ctx); err != nil {
+		return err
 	}
 
-	return
+	return b.checkCost(ctx, statements...)
+}
+
+// checkStatements run the checks of the SQL itself, for the paths without an Executor of the builder
+func (b *Builder) checkStatements(statements ...statement) error {
+	for _, stmt := range statements {
+		if err := checkSingleStatement(stmt.sql); err != nil {
+			return err
+		}
+	}
+
+	if err := b.checkReadOnly(statements...); err != nil {
+		return err
+	}
+
+	return b.checkParse(statements...)
 }
 
 // PagingFunc paging
 func (b *Builder) PagingFunc(f ExecFunc) *Pagination {
+	pagination, err := b.PagingFuncE(f)
+	if err != nil {
+		logger.Printf("paging: %v", err)
+	}
+	if pagination == nil {
+		pagination = &Pagination{}
+	}
+
+	return pagination
+}
+
+// PagingFuncE paging, returns the error instead of logging it
+func (b *Builder) PagingFuncE(f ExecFunc) (*Pagination, error) {
 	if b.page < 1 {
 		b.page = 1
 	}
-	var offset = (b.page - 1) * b.limit
-	var done = make(chan bool, 1)
-	var pagination Pagination
-	var count int
 
+	var paging = func() (*Pagination, error) {
+		pagination, err := b.pagingFunc(f)
+		if err != nil {
+			return pagination, err
+		}
+
+		b.attachWindow(pagination)
+		return pagination, b.attachFacets(pagination, true, func(stmt statement) (*sql.Rows, error) {
+			return b.db.Raw(stmt.sql, stmt.values...).Rows()
+		})
+	}
+
+	pagination, err := b.sharedPaging(context.Background(), func() (*Pagination, error) {
+		if b.cache != nil {
+			sqlString, _ := b.build()
+			return b.cachedPaging(context.Background(), statement{sqlString, b.values()}, func() (*Pagination, error) {
+				return b.pageInRange(paging)
+			})
+		}
+
+		return b.pageInRange(paging)
+	})
+	if err == nil {
+		b.prefetchFunc(pagination, f)
+	}
+
+	return pagination, err
+}
+
+// pagingFunc paging on gorm
+func (b *Builder) pagingFunc(f ExecFunc) (*Pagination, error) {
 	sqlString, countSQLString := b.build()
+	countSQLString = b.summaryCountSQL(countSQLString)
 
-	var values = []interface{}{}
-	values = append(values, b.whereValues...)
+	values, countValues := b.boundValues()
 
-	var countSQL = b.db.Raw(fmt.Sprintf("SELECT COUNT(1) FROM (%s) t", countSQLString), values...)
-	go b.count(countSQL, done, &count)
+	if err := b.beforeExec(context.Background(), statement{sqlString, values}, statement{countSQLString, countValues}); err != nil {
+		return nil, err
+	}
 
-	result, err := f(b.db, b.dbb.db.Raw(sqlString, values...))
+	release, err := b.acquire(context.Background())
 	if err != nil {
-		b.db.CustomLogger.Error(err)
+		return nil, err
+	}
+	defer release()
+
+	var pagination *Pagination
+	err = b.gormSession(context.Background(), func(db DB, dedicated bool) error {
+		pagination, err = b.pagingOn(db, dedicated, f, statement{sqlString, values}, statement{countSQLString, countValues})
+		return err
+	})
+	return pagination, err
+}
+
+// pagingOn run the data and count queries of pagingFunc on db, one after the other on a dedicated session
+func (b *Builder) pagingOn(db DB, dedicated bool, f ExecFunc, data statement, countStmt statement) (*Pagination, error) {
+	if b.lazyCount {
+		result, err := f(db, WrapGorm(db.Raw(data.sql, data.values...)))
+		return b.paginateLazy(result, func(ctx context.Context) (count int, summary map[string]interface{}, err error) {
+			err = b.gormSession(ctx, func(db DB, dedicated bool) (err error) {
+				count, summary, err = scanSummary(db.WithContext(ctx).Raw(countStmt.sql, countStmt.values...).Rows())
+				return err
+			})
+			return count, summary, err
+		}), err
+	}
+
+	var done = make(chan bool, 1)
+	var count int
+	var summary map[string]interface{}
+	var summaryErr error
+	var counting = func() {
+		if b.summary != "" {
+			count, summary, summaryErr = scanSummary(db.Raw(countStmt.sql, countStmt.values...).Rows())
+			done <- true
+			return
+		}
+
+		var countSQL = WrapGorm(db.Raw(countStmt.sql, countStmt.values...))
+		b.count(countSQL, done, &count)
+	}
+	if dedicated {
+		counting()
+	} else {
+		go counting()
 	}
+
+	result, err := f(db, WrapGorm(db.Raw(data.sql, data.values...)))
 	<-done
 	close(done)
 
+	var pagination = b.paginate(count, result)
+	pagination.Summary = summary
+	if err == nil {
+		err = summaryErr
+	}
+	return pagination, err
+}
+
+// paginate fill the pagination of the current page from the total count
+func (b *Builder) paginate(count int, result interface{}) *Pagination {
+	// without limit the query ignores the page, every record is on page 1
+	var page = b.page
+	if b.limit <= 0 {
+		page = 1
+	}
+
+	var pagination Pagination
 	pagination.TotalRecord = count
 	pagination.Records = result
-	pagination.Page = b.page
-	pagination.Offset = offset
+	pagination.Page = page
+	pagination.Offset = b.offset()
 
 	if b.limit > 0 {
 		pagination.PerPage = b.limit
@@ -244,16 +703,16 @@ func (b *Builder) PagingFunc(f ExecFunc) *Pagination {
 		pagination.PerPage = count
 	}
 
-	if b.page > 1 {
-		pagination.PrevPage = b.page - 1
+	if page > 1 {
+		pagination.PrevPage = page - 1
 	} else {
-		pagination.PrevPage = b.page
+		pagination.PrevPage = page
 	}
 
-	if b.page == pagination.TotalPage {
-		pagination.NextPage = b.page
+	if page == pagination.TotalPage {
+		pagination.NextPage = page
 	} else {
-		pagination.NextPage = b.page + 1
+		pagination.NextPage = page + 1
 	}
 
 	pagination.HasNext = pagination.TotalPage > pagination.Page
@@ -263,17 +722,41 @@ func (b *Builder) PagingFunc(f ExecFunc) *Pagination {
 		pagination.NextPage = pagination.Page
 	}
 
+	if b.strict {
+		b.strictPages(&pagination)
+	}
+
 	return &pagination
 }
 
-// ExecFunc exec
-func (b *Builder) ExecFunc(f ExecFunc, dest interface{}) error {
+// rawQuery run f on the data query on gorm after the pre-execution checks, in the session of the builder
+func (b *Builder) rawQuery(f func(db DB, rawSQL DB) error) error {
 	sqlString, _ := b.build()
 
-	var values = []interface{}{}
-	values = append(values, b.whereValues...)
+	var values = b.values()
+
+	if err := b.beforeExec(context.Background(), statement{sqlString, values}); err != nil {
+		return err
+	}
 
-	result, err := f(b.db, b.db.WithGorm(b.db.Raw(sqlString, values...)))
+	release, err := b.acquire(context.Background())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return b.gormSession(context.Background(), func(db DB, dedicated bool) error {
+		return f(db, WrapGorm(db.Raw(sqlString, values...)))
+	})
+}
+
+// ExecFunc exec. Prefer Exec, which returns the result typed instead of assigning it by reflection.
+func (b *Builder) ExecFunc(f ExecFunc, dest interface{}) error {
+	var result interface{}
+	var err = b.rawQuery(func(db DB, rawSQL DB) (err error) {
+		result, err = f(db, rawSQL)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -310,27 +793,41 @@ func (b *Builder) ExecFunc(f ExecFunc, dest interface{}) error {
 // Scan scan
 func (b *Builder) Scan(dest interface{}) error {
 	sqlString, _ := b.build()
+	var values = b.values()
+
+	if err := b.beforeExec(context.Background(), statement{sqlString, values}); err != nil {
+		return err
+	}
 
-	var err = b.db.Raw(sqlString, b.whereValues...).Scan(dest).Error
+	release, err := b.acquire(context.Background())
 	if err != nil {
-		b.db.CustomLogger.Error(err)
 		return err
 	}
+	defer release()
 
-	return nil
+	return b.gormSession(context.Background(), func(db DB, dedicated bool) error {
+		return db.Raw(sqlString, values...).Scan(dest).Error
+	})
 }
 
 // ScanRow scan
 func (b *Builder) ScanRow(dest interface{}) error {
 	sqlString, _ := b.build()
+	var values = b.values()
 
-	var err = b.db.Raw(sqlString, b.whereValues...).Row().Scan(dest)
+	if err := b.beforeExec(context.Background(), statement{sqlString, values}); err != nil {
+		return err
+	}
+
+	release, err := b.acquire(context.Background())
 	if err != nil {
-		b.db.CustomLogger.Error(err)
 		return err
 	}
+	defer release()
 
-	return nil
+	return b.session(context.Background(), func(exec Executor, dedicated bool) error {
+		return exec.QueryRowContext(context.Background(), sqlString, values...).Scan(dest)
+	})
 }
 
 // toPtr wraps the given value with pointer: V => *V, *V => **V, etc.
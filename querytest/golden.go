@@ -0,0 +1,115 @@
+package querytest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv environment variable rewriting the golden files instead of comparing
+const UpdateEnv = "QUERYTEST_UPDATE"
+
+// NormalizeSQL collapse whitespace and rewrite the placeholders to ? outside quotes
+func NormalizeSQL(sql string) string {
+	var sb strings.Builder
+	var quote byte
+	var space = false
+
+	var flushSpace = func(next byte) {
+		if space && sb.Len() > 0 && next != ')' && next != ',' && !strings.HasSuffix(sb.String(), "(") {
+			sb.WriteByte(' ')
+		}
+		space = false
+	}
+
+	for i := 0; i < len(sql); i++ {
+		var c = sql[i]
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			for i+1 < len(sql) && isDigit(sql[i+1]) {
+				i++
+			}
+			flushSpace('?')
+			sb.WriteByte('?')
+			continue
+		case c == '@' && i+2 < len(sql) && sql[i+1] == 'p' && isDigit(sql[i+2]):
+			i++
+			for i+1 < len(sql) && isDigit(sql[i+1]) {
+				i++
+			}
+			flushSpace('?')
+			sb.WriteByte('?')
+			continue
+		}
+
+		flushSpace(c)
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// MatchSQL compare expected and actual after NormalizeSQL, a sqlmock QueryMatcherFunc
+func MatchSQL(expected string, actual string) error {
+	if want, got := NormalizeSQL(expected), NormalizeSQL(actual); want != got {
+		return fmt.Errorf("querytest: SQL mismatch\nwant: %s\n got: %s", want, got)
+	}
+	return nil
+}
+
+// AssertSQL fail t when actual doesn't match expected after NormalizeSQL
+func AssertSQL(t testing.TB, expected string, actual string) {
+	t.Helper()
+
+	if err := MatchSQL(expected, actual); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// AssertGolden compare the SQL and its args with the golden file at path
+func AssertGolden(t testing.TB, path string, sql string, args ...interface{}) {
+	t.Helper()
+
+	var got = NormalizeSQL(sql) + "\n"
+	if len(args) > 0 {
+		got += fmt.Sprintf("-- args: %v\n", args)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("querytest: read golden file: %v (run with %s=1 to create it)", err, UpdateEnv)
+	}
+	if string(want) != got {
+		t.Fatalf("querytest: %s mismatch\nwant: %s got: %s", path, want, got)
+	}
+}
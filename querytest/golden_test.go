@@ -0,0 +1,36 @@
+package querytest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	query "github.com/thaitanloi365/go-query"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	var got = NormalizeSQL("\n\tSELECT  *\n FROM users WHERE id IN ( $1 , $2 )  AND name = 'a  b' AND note = @p3\n")
+	if got != "SELECT * FROM users WHERE id IN (?, ?) AND name = 'a  b' AND note = ?" {
+		t.Fatalf("unexpected sql: %s", got)
+	}
+
+	if err := MatchSQL("SELECT * FROM t WHERE a = ?", "SELECT *\nFROM t\nWHERE a = $1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "users.golden")
+	var want = "SELECT * FROM users WHERE name = ? ORDER BY id LIMIT 10\n-- args: [a]\n"
+	if err := ioutil.WriteFile(path, []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, args := query.New(nil, "SELECT * FROM users").
+		Where("name = ?", "a").
+		OrderBy("id").
+		Limit(10).
+		ToSQL()
+
+	AssertGolden(t, path, sql, args...)
+}
@@ -0,0 +1,382 @@
+// Package querytest fake executor for unit testing code built on query.Builder without a database.
+package querytest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	query "github.com/thaitanloi365/go-query"
+)
+
+// ErrUnexpectedQuery returned when no canned result matches a statement
+var ErrUnexpectedQuery = errors.New("querytest: unexpected query")
+
+// Call statement recorded by the executor, SQL keeps the ? placeholders of the builder
+type Call struct {
+	SQL  string
+	Args []interface{}
+}
+
+type result struct {
+	match    string
+	count    bool
+	columns  []string
+	rows     [][]interface{}
+	affected int64
+	err      error
+}
+
+// Executor fake query.Executor recording the statements, the latest matching result wins
+type Executor struct {
+	dialect string
+	db      *sql.DB
+
+	mu      sync.Mutex
+	calls   []Call
+	results []result
+	holds   []*Barrier
+}
+
+// Barrier holds the statements containing its match until released
+type Barrier struct {
+	match    string
+	arrived  chan struct{}
+	released chan struct{}
+	once     sync.Once
+}
+
+var _ query.Executor = (*Executor)(nil)
+
+var executors sync.Map
+var executorSeq int64
+
+func init() {
+	sql.Register("querytest", fakeDriver{})
+}
+
+// New init a fake executor reporting dialect, e.g. query.DialectPostgres
+func New(dialect string) *Executor {
+	var e = &Executor{dialect: dialect}
+	var dsn = fmt.Sprintf("querytest-%d", atomic.AddInt64(&executorSeq, 1))
+	executors.Store(dsn, e)
+
+	// sql.Open never fails for a registered driver
+	e.db, _ = sql.Open("querytest", dsn)
+	return e
+}
+
+// Rows answer data queries containing match with rows, empty match answers every data query
+func (e *Executor) Rows(match string, columns []string, rows ...[]interface{}) *Executor {
+	return e.add(result{match: match, columns: columns, rows: rows})
+}
+
+// Count answer the count queries with n
+func (e *Executor) Count(n int) *Executor {
+	return e.add(result{count: true, columns: []string{"count"}, rows: [][]interface{}{{int64(n)}}})
+}
+
+// Exec answer statements containing match run with ExecContext, reporting affected rows
+func (e *Executor) Exec(match string, affected int64) *Executor {
+	return e.add(result{match: match, affected: affected})
+}
+
+// Error fail any statement containing match with err
+func (e *Executor) Error(match string, err error) *Executor {
+	return e.add(result{match: match, err: err})
+}
+
+func (e *Executor) add(r result) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.results = append(e.results, r)
+	return e
+}
+
+// Hold block the statements containing match until the returned barrier is released
+func (e *Executor) Hold(match string) *Barrier {
+	var b = &Barrier{match: match, arrived: make(chan struct{}), released: make(chan struct{})}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.holds = append(e.holds, b)
+	return b
+}
+
+// Arrived closed once a statement is held by the barrier
+func (b *Barrier) Arrived() <-chan struct{} {
+	return b.arrived
+}
+
+// Release run the held statements and stop holding new ones
+func (b *Barrier) Release() {
+	close(b.released)
+}
+
+// wait block query on the matching barriers until released or ctx is done
+func (e *Executor) wait(ctx context.Context, query string) error {
+	e.mu.Lock()
+	var holds = append([]*Barrier(nil), e.holds...)
+	e.mu.Unlock()
+
+	for _, b := range holds {
+		if !strings.Contains(query, b.match) {
+			continue
+		}
+
+		b.once.Do(func() { close(b.arrived) })
+		select {
+		case <-b.released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// DB database on the fake driver recording the statements, e.g. to open gorm on it
+func (e *Executor) DB() *sql.DB {
+	return e.db
+}
+
+// Calls statements run so far, in execution order
+func (e *Executor) Calls() []Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Call(nil), e.calls...)
+}
+
+// LastCall last statement run, zero Call if none
+func (e *Executor) LastCall() Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.calls) == 0 {
+		return Call{}
+	}
+	return e.calls[len(e.calls)-1]
+}
+
+// Reset forget the recorded calls, canned results are kept
+func (e *Executor) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls = nil
+}
+
+// QueryContext ...
+func (e *Executor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	e.record(query, args)
+	return e.db.QueryContext(context.WithValue(ctx, recordedKey{}, true), query, args...)
+}
+
+// QueryRowContext ...
+func (e *Executor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	e.record(query, args)
+	return e.db.QueryRowContext(context.WithValue(ctx, recordedKey{}, true), query, args...)
+}
+
+// ExecContext ...
+func (e *Executor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.record(query, args)
+	return e.db.ExecContext(context.WithValue(ctx, recordedKey{}, true), query, args...)
+}
+
+// Transaction run fn on the executor itself, the fake has no transactions
+func (e *Executor) Transaction(ctx context.Context, fn func(tx query.Executor) error) error {
+	return fn(e)
+}
+
+// Dialect ...
+func (e *Executor) Dialect() string {
+	return e.dialect
+}
+
+// recordedKey marks the statements already recorded by the executor
+type recordedKey struct{}
+
+func (e *Executor) record(query string, args []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls = append(e.calls, Call{SQL: query, Args: args})
+}
+
+// lookup latest result matching query
+func (e *Executor) lookup(query string) (result, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var count = isCountQuery(query)
+	for i := len(e.results) - 1; i >= 0; i-- {
+		var r = e.results[i]
+		if !strings.Contains(query, r.match) {
+			continue
+		}
+		if r.err != nil {
+			return r, r.err
+		}
+		if r.count == count {
+			return r, nil
+		}
+	}
+
+	return result{}, fmt.Errorf("%w: %s", ErrUnexpectedQuery, query)
+}
+
+// isCountQuery report the count statements built by query.Builder
+func isCountQuery(query string) bool {
+	return strings.Contains(query, "SELECT COUNT(1) FROM (") && strings.HasSuffix(query, ") t")
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	e, ok := executors.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("querytest: unknown executor %s", name)
+	}
+	return &fakeConn{e.(*Executor)}, nil
+}
+
+type fakeConn struct {
+	e *Executor
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("querytest: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// CheckNamedValue accept any arg, the recorded call keeps the original values
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+// recordDirect record the statements run on DB
+func (c *fakeConn) recordDirect(ctx context.Context, query string, args []driver.NamedValue) {
+	if ctx.Value(recordedKey{}) != nil {
+		return
+	}
+
+	var values = make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.e.record(query, values)
+}
+
+// QueryContext answer each statement of a multi-statement batch with a result set
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.recordDirect(ctx, query, args)
+	if err := c.e.wait(ctx, query); err != nil {
+		return nil, err
+	}
+
+	var rows = &fakeRows{}
+	for i, statement := range strings.Split(query, "; ") {
+		r, err := c.e.lookup(statement)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			rows.columns, rows.rows = r.columns, r.rows
+			continue
+		}
+		rows.sets = append(rows.sets, &fakeRows{columns: r.columns, rows: r.rows})
+	}
+	return rows, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.recordDirect(ctx, query, args)
+	if err := c.e.wait(ctx, query); err != nil {
+		return nil, err
+	}
+
+	r, err := c.e.lookup(query)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(r.affected), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+	pos     int
+	sets    []*fakeRows
+}
+
+func (r *fakeRows) HasNextResultSet() bool {
+	return len(r.sets) > 0
+}
+
+func (r *fakeRows) NextResultSet() error {
+	if len(r.sets) == 0 {
+		return io.EOF
+	}
+
+	var next = r.sets[0]
+	r.columns, r.rows, r.pos, r.sets = next.columns, next.rows, 0, r.sets[1:]
+	return nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+
+	var row = r.rows[r.pos]
+	r.pos++
+	for i := range dest {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+
+		value, err := driver.DefaultParameterConverter.ConvertValue(row[i])
+		if err != nil {
+			return err
+		}
+		dest[i] = value
+	}
+
+	return nil
+}
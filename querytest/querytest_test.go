@@ -0,0 +1,1336 @@
+package querytest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	query "github.com/thaitanloi365/go-query"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestExecutorPagingRows(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM users", []string{"id", "name"}, []interface{}{1, "a"}, []interface{}{2, "b"}).
+		Count(12)
+
+	var b = query.NewWithExecutor(exec, "SELECT id, name FROM users").
+		Where("name LIKE ?", "%a%").
+		Limit(2).
+		Page(3)
+
+	type user struct {
+		ID   int
+		Name string
+	}
+
+	pagination, err := b.PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+		var users = []user{}
+		for rows.Next() {
+			var u user
+			if err := rows.Scan(&u.ID, &u.Name); err != nil {
+				return nil, err
+			}
+			users = append(users, u)
+		}
+		return users, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pagination.TotalRecord != 12 || len(pagination.Records.([]user)) != 2 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	for _, call := range calls {
+		if len(call.Args) != 1 || call.Args[0] != "%a%" {
+			t.Fatalf("unexpected args: %v", call.Args)
+		}
+	}
+}
+
+func TestExecutorUnexpectedQuery(t *testing.T) {
+	var exec = New(query.DialectPostgres).Count(1)
+
+	_, err := query.NewWithExecutor(exec, "SELECT * FROM users").Rows(context.Background())
+	if !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery, got %v", err)
+	}
+
+	var boom = errors.New("boom")
+	exec.Error("FROM users", boom)
+	if _, err = query.NewWithExecutor(exec, "SELECT * FROM users").Count(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+}
+
+func TestExecutorLazyCount(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM users", []string{"id"}, []interface{}{1}).
+		Count(7)
+
+	pagination, err := query.NewWithExecutor(exec, "SELECT id FROM users").
+		WithLazyCount().
+		Limit(5).
+		PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exec.Calls()) != 1 || pagination.TotalRecord != 0 {
+		t.Fatalf("count should be lazy: %v", exec.Calls())
+	}
+	pagination.Metadata = "kept"
+
+	// a failed count isn't kept
+	var canceled, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := pagination.Total(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		total, err := pagination.Total(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 7 || pagination.TotalPage != 2 || !pagination.HasNext || pagination.Metadata != "kept" {
+			t.Fatalf("unexpected pagination: %+v", pagination)
+		}
+	}
+	if len(exec.Calls()) != 3 {
+		t.Fatalf("count should run once after the failure: %v", exec.Calls())
+	}
+}
+
+func TestExecutorPagingStream(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM events", []string{"id"}, []interface{}{1}, []interface{}{2}, []interface{}{3}).
+		Count(3)
+
+	pagination, err := query.NewWithExecutor(exec, "SELECT id FROM events").
+		WithStreamBudget(5, 0).
+		PagingStream(context.Background(), func(row query.Row) error {
+			t.Fatalf("page within budget should not stream")
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pagination.Streamed || len(pagination.Records.([]query.Row)) != 3 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	var streamed = []interface{}{}
+	pagination, err = query.NewWithExecutor(exec, "SELECT id FROM events").
+		WithStreamBudget(2, 0).
+		PagingStream(context.Background(), func(row query.Row) error {
+			streamed = append(streamed, row["id"])
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pagination.Streamed || pagination.Records != nil || len(streamed) != 3 || streamed[0] != int64(1) {
+		t.Fatalf("unexpected stream: %+v %v", pagination, streamed)
+	}
+}
+
+func TestExecutorExportCSV(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM orders", []string{"id", "note"}, []interface{}{1, "a"}, []interface{}{2, nil}).
+		Rows("MIN(t.export_key)", []string{"min", "max"}, []interface{}{1, 4})
+
+	var out bytes.Buffer
+	var err = query.NewWithExecutor(exec, "SELECT id, note FROM orders o").
+		Where("o.status = ?", "paid").
+		Limit(10).
+		ExportCSV(context.Background(), &out, query.ExportOptions{
+			Header:    true,
+			KeyColumn: "o.id",
+			Segments:  2,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "id,note\n1,a\n2,\n1,a\n2,\n" {
+		t.Fatalf("unexpected export: %q", out.String())
+	}
+
+	var ranges = map[string]bool{}
+	for _, call := range exec.Calls()[1:] {
+		if len(call.Args) != 3 {
+			t.Fatalf("unexpected args: %v", call.Args)
+		}
+		ranges[fmt.Sprint(call.Args[1:])] = true
+	}
+	if !ranges["[1 3]"] || !ranges["[3 5]"] {
+		t.Fatalf("unexpected ranges: %v", ranges)
+	}
+
+	var empty = New(query.DialectPostgres).
+		Rows("FROM orders", []string{"id", "note"}).
+		Rows("MIN(t.export_key)", []string{"min", "max"}, []interface{}{nil, nil})
+	out.Reset()
+	err = query.NewWithExecutor(empty, "SELECT id, note FROM orders o").
+		ExportCSV(context.Background(), &out, query.ExportOptions{Header: true, KeyColumn: "o.id", Segments: 2})
+	if err != nil || out.String() != "id,note\n" {
+		t.Fatalf("empty export should keep the header: %q %v", out.String(), err)
+	}
+
+	var failing = New(query.DialectPostgres).Error("MIN(t.export_key)", errors.New("timeout"))
+	err = query.NewWithExecutor(failing, "SELECT id, note FROM orders o").
+		ExportCSV(context.Background(), &out, query.ExportOptions{KeyColumn: "o.id", Segments: 2})
+	if err == nil || err.Error() != "timeout" {
+		t.Fatalf("expected the key range error, got %v", err)
+	}
+}
+
+func TestExecutorCached(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM products", []string{"id"}, []interface{}{1}).
+		Count(1)
+
+	query.SetCacheStore(query.NewMemoryCache(10))
+	defer query.SetCacheStore(query.NewMemoryCache(10000))
+
+	var paging = func() *query.Pagination {
+		pagination, err := query.NewWithExecutor(exec, "SELECT id FROM products").
+			Cached(time.Minute, "products").
+			PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+				var ids = []int{}
+				for rows.Next() {
+					var id int
+					rows.Scan(&id)
+					ids = append(ids, id)
+				}
+				return ids, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pagination
+	}
+
+	paging()
+	var cached = paging()
+	if len(exec.Calls()) != 2 {
+		t.Fatalf("second page should be cached: %v", exec.Calls())
+	}
+	if data, _ := json.Marshal(cached.Records); string(data) != "[1]" || cached.TotalRecord != 1 {
+		t.Fatalf("unexpected cached pagination: %+v", cached)
+	}
+
+	if err := query.InvalidateCache(context.Background(), "products"); err != nil {
+		t.Fatal(err)
+	}
+	paging()
+	if len(exec.Calls()) != 4 {
+		t.Fatalf("invalidated page should run again: %v", exec.Calls())
+	}
+
+	var listener = query.NewListener("").InvalidateCache("products")
+	listener.Notify("products", "")
+	paging()
+	if len(exec.Calls()) != 6 {
+		t.Fatalf("notified page should run again: %v", exec.Calls())
+	}
+}
+
+func TestExecutorCachedMetadata(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM tickets", []string{"id", "status"}, []interface{}{1, "open"}).
+		Rows("SUM(points)", []string{"count", "total_points"}, []interface{}{2, 5}).
+		Rows("GROUP BY status", []string{"status", "count"}, []interface{}{"open", 2}).
+		Count(1)
+
+	query.SetCacheStore(query.NewMemoryCache(10))
+	defer query.SetCacheStore(query.NewMemoryCache(10000))
+
+	var paging = func() *query.Pagination {
+		pagination, err := query.NewWithExecutor(exec, "SELECT id, status FROM tickets").
+			WithFacets("status").
+			WithSummary("SUM(points) AS total_points").
+			Cached(time.Minute).
+			PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+				return nil, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pagination
+	}
+
+	paging()
+	var cached = paging()
+	if len(exec.Calls()) != 3 {
+		t.Fatalf("second page should be cached: %v", exec.Calls())
+	}
+	if facets, ok := cached.Metadata.(query.Facets); !ok || facets["status"]["open"] != 2 {
+		t.Fatalf("unexpected cached facets: %#v", cached.Metadata)
+	}
+	if cached.Summary["total_points"] != 5.0 {
+		t.Fatalf("unexpected cached summary: %v", cached.Summary)
+	}
+
+	var streamed int
+	for i := 0; i < 2; i++ {
+		_, err := query.NewWithExecutor(exec, "SELECT id, status FROM tickets").
+			Cached(time.Minute).
+			WithStreamBudget(0, 1).
+			PagingStream(context.Background(), func(row query.Row) error {
+				streamed++
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if streamed != 2 {
+		t.Fatalf("cached stream should reach the stream func, got %d rows", streamed)
+	}
+}
+
+func TestMemoryCacheEvictsTags(t *testing.T) {
+	var store = query.NewMemoryCache(2)
+	for i := 0; i < 5; i++ {
+		if err := store.Set(context.Background(), fmt.Sprintf("query:tag:%d", i), []byte("1"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var kept int
+	for i := 0; i < 5; i++ {
+		if _, ok, _ := store.Get(context.Background(), fmt.Sprintf("query:tag:%d", i)); ok {
+			kept++
+		}
+	}
+	if kept != 2 {
+		t.Fatalf("expected 2 entries, got %d", kept)
+	}
+}
+
+type cachedProduct struct {
+	ID   int
+	Name string
+}
+
+func TestExecutorCachedGob(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM products", []string{"id", "name"}, []interface{}{1, "a"}).
+		Count(1)
+	query.RegisterCacheType([]*cachedProduct{})
+
+	var store = query.NewMemoryCache(10)
+	var paging = func() *query.Pagination {
+		pagination, err := query.NewWithExecutor(exec, "SELECT id, name FROM products").
+			Cached(time.Minute).
+			WithCacheStore(store).
+			WithCacheCodec(query.GobCodec).
+			PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+				var products = []*cachedProduct{}
+				for rows.Next() {
+					var product cachedProduct
+					rows.Scan(&product.ID, &product.Name)
+					products = append(products, &product)
+				}
+				return products, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pagination
+	}
+
+	paging()
+	var cached = paging()
+	if len(exec.Calls()) != 2 {
+		t.Fatalf("second page should be cached: %v", exec.Calls())
+	}
+	products, ok := cached.Records.([]*cachedProduct)
+	if !ok || len(products) != 1 || products[0].Name != "a" {
+		t.Fatalf("unexpected cached records: %#v", cached.Records)
+	}
+}
+
+func TestExecutorPageOutOfRange(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM tasks", []string{"id"}, []interface{}{1}).
+		Count(25)
+	var discard = func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	}
+
+	pagination, err := query.NewWithExecutor(exec, "SELECT id FROM tasks").Limit(10).Page(5).
+		PagingRows(context.Background(), discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pagination.Page != 5 || pagination.PrevPage != 4 {
+		t.Fatalf("default should keep the pagination: %+v", pagination)
+	}
+
+	pagination, err = query.NewWithExecutor(exec, "SELECT id FROM tasks").Limit(10).Page(5).
+		WithPageOutOfRange(query.PageOutOfRangeEmpty).
+		PagingRows(context.Background(), discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pagination.Page != 5 || pagination.PrevPage != 3 || pagination.HasNext {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	exec.Reset()
+	pagination, err = query.NewWithExecutor(exec, "SELECT id FROM tasks").Limit(10).Page(5).
+		WithPageOutOfRange(query.PageOutOfRangeClamp).
+		PagingRows(context.Background(), discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clamped = false
+	for _, call := range exec.Calls() {
+		clamped = clamped || call.SQL == "SELECT id FROM tasks LIMIT 10 OFFSET 20"
+	}
+	if pagination.Page != 3 || !pagination.HasPrev || !clamped || len(exec.Calls()) != 4 {
+		t.Fatalf("unexpected pagination: %+v %v", pagination, exec.Calls())
+	}
+
+	_, err = query.NewWithExecutor(exec, "SELECT id FROM tasks").Limit(10).Page(5).
+		WithPageOutOfRange(query.PageOutOfRangeError).
+		PagingRows(context.Background(), discard)
+	if !errors.Is(err, query.ErrPageOutOfRange) {
+		t.Fatalf("expected ErrPageOutOfRange, got %v", err)
+	}
+
+	// page 5 of an empty result is out of range too, page 1 isn't
+	var empty = New(query.DialectPostgres).
+		Rows("FROM tasks", []string{"id"}).
+		Count(0)
+	_, err = query.NewWithExecutor(empty, "SELECT id FROM tasks").Limit(10).Page(5).
+		WithPageOutOfRange(query.PageOutOfRangeError).
+		PagingRows(context.Background(), discard)
+	if !errors.Is(err, query.ErrPageOutOfRange) {
+		t.Fatalf("expected ErrPageOutOfRange on an empty result, got %v", err)
+	}
+	if _, err = query.NewWithExecutor(empty, "SELECT id FROM tasks").Limit(10).Page(1).
+		WithPageOutOfRange(query.PageOutOfRangeError).
+		PagingRows(context.Background(), discard); err != nil {
+		t.Fatalf("page 1 of an empty result: %v", err)
+	}
+
+	pagination, err = query.NewWithExecutor(empty, "SELECT id FROM tasks").Limit(10).Page(5).
+		WithPageOutOfRange(query.PageOutOfRangeClamp).
+		PagingRows(context.Background(), discard)
+	if err != nil || pagination.Page != 1 {
+		t.Fatalf("expected page 1, got %+v: %v", pagination, err)
+	}
+}
+
+func TestExecutorFacets(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM tickets", []string{"id", "status"}, []interface{}{1, "open"}).
+		Count(17).
+		Rows("GROUP BY status", []string{"status", "count"}, []interface{}{"open", 12}, []interface{}{"closed", 4}, []interface{}{nil, 1}).
+		Rows("GROUP BY category", []string{"category", "count"}, []interface{}{"bug", 17})
+
+	var b = query.NewWithExecutor(exec, "SELECT id, status, category FROM tickets").
+		Where("team_id = ?", 3).
+		WithFacets("status", "category").
+		Limit(1)
+
+	pagination, err := b.PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var facets, ok = pagination.Metadata.(query.Facets)
+	if !ok || facets["status"]["open"] != 12 || facets["status"]["null"] != 1 || facets["category"]["bug"] != 17 {
+		t.Fatalf("unexpected facets: %#v", pagination.Metadata)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	for _, call := range calls[2:] {
+		if call.SQL != "SELECT status, COUNT(1) FROM (SELECT id, status, category FROM tickets WHERE team_id = ?) t GROUP BY status" &&
+			call.SQL != "SELECT category, COUNT(1) FROM (SELECT id, status, category FROM tickets WHERE team_id = ?) t GROUP BY category" {
+			t.Fatalf("unexpected facet query: %s", call.SQL)
+		}
+		if len(call.Args) != 1 || call.Args[0] != 3 {
+			t.Fatalf("unexpected args: %v", call.Args)
+		}
+	}
+}
+
+func TestExecutorSummary(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM orders", []string{"id", "amount"}, []interface{}{1, 20}).
+		Rows("SUM(amount)", []string{"count", "total_amount", "avg_amount"}, []interface{}{3, 60, 20.0})
+
+	var b = query.NewWithExecutor(exec, "SELECT id, amount FROM orders").
+		Where("customer_id = ?", 7).
+		WithSummary("SUM(amount) AS total_amount, AVG(amount) AS avg_amount").
+		OrderBy("id").
+		Limit(1)
+
+	pagination, err := b.PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pagination.TotalRecord != 3 || pagination.Summary["total_amount"] != int64(60) || pagination.Summary["avg_amount"] != 20.0 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	var found bool
+	for _, call := range calls {
+		if call.SQL == "SELECT COUNT(1), SUM(amount) AS total_amount, AVG(amount) AS avg_amount FROM (SELECT id, amount FROM orders WHERE customer_id = ?) t" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("summary not computed with the count: %v", calls)
+	}
+
+	lazy, err := b.WithLazyCount().PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lazy.Summary != nil {
+		t.Fatalf("lazy summary should wait for Total: %v", lazy.Summary)
+	}
+	if total, err := lazy.Total(context.Background()); err != nil || total != 3 || lazy.Summary["total_amount"] != int64(60) {
+		t.Fatalf("unexpected lazy total %d %v: %v", total, lazy.Summary, err)
+	}
+}
+
+func TestExecutorAggregates(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("SUM(amount)", []string{"sum"}, []interface{}{60}).
+		Rows("AVG(score)", []string{"avg"}, []interface{}{nil}).
+		Rows("MAX(created_at)", []string{"max"}, []interface{}{time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)})
+
+	var b = query.NewWithExecutor(exec, "SELECT amount, score, created_at FROM orders").Where("customer_id = ?", 7)
+
+	sum, err := b.SumInt(context.Background(), "amount")
+	if err != nil || sum != 60 {
+		t.Fatalf("unexpected sum %d: %v", sum, err)
+	}
+	if call := exec.LastCall(); call.SQL != "SELECT COALESCE(SUM(amount), 0) FROM (SELECT amount, score, created_at FROM orders WHERE customer_id = ?) t" {
+		t.Fatalf("unexpected query: %s", call.SQL)
+	}
+
+	avg, err := b.Avg(context.Background(), "score")
+	if err != nil || avg != 0 {
+		t.Fatalf("unexpected avg %v: %v", avg, err)
+	}
+
+	var max sql.NullTime
+	if err := b.Max(context.Background(), "created_at", &max); err != nil || !max.Valid || max.Time.Year() != 2021 {
+		t.Fatalf("unexpected max %v: %v", max, err)
+	}
+
+	if _, err := b.Sum(context.Background(), "amount); DROP TABLE orders; --"); !errors.Is(err, query.ErrInvalidColumn) {
+		t.Fatalf("expected ErrInvalidColumn, got %v", err)
+	}
+}
+
+func TestExec(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM users", []string{"id", "email"}, []interface{}{1, "a@example.com"}, []interface{}{2, "b@example.com"})
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: exec.DB()}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	type user struct {
+		ID    int
+		Email string
+	}
+
+	users, err := query.Exec(query.New(query.WrapGorm(gdb), "SELECT * FROM users u").Limit(10), func(db, rawSQL query.DB) ([]*user, error) {
+		var users []*user
+		var err = rawSQL.GetGorm().Scan(&users).Error
+		return users, err
+	})
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if len(users) != 2 || users[1].Email != "b@example.com" {
+		t.Fatalf("unexpected users %+v", users)
+	}
+}
+
+func TestGormReadOnlyTx(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM users", []string{"count"}, []interface{}{3})
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: exec.DB()}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	var count int
+	if err := query.New(query.WrapGorm(gdb), "SELECT COUNT(1) FROM users").ReadOnlyTx().Scan(&count); !errors.Is(err, ErrUnexpectedQuery) || !strings.Contains(err.Error(), "SET TRANSACTION READ ONLY") {
+		t.Fatalf("expected the read-only transaction, got %v", err)
+	}
+
+	exec.Exec("SET TRANSACTION READ ONLY", 0)
+	if err := query.New(query.WrapGorm(gdb), "SELECT COUNT(1) FROM users").ReadOnlyTx().Scan(&count); err != nil || count != 3 {
+		t.Fatalf("unexpected count %d: %v", count, err)
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM posts", []string{"author_id", "title"}, []interface{}{1, "a"}, []interface{}{1, "b"}, []interface{}{3, "c"})
+
+	var loader = query.NewLoader(context.Background(), func() *query.Builder {
+		return query.NewWithExecutor(exec, "SELECT author_id, title FROM posts").Limit(2).Page(3)
+	}, "author_id", func(rows *sql.Rows) (int, string, error) {
+		var authorID int
+		var title string
+		err := rows.Scan(&authorID, &title)
+		return authorID, title, err
+	})
+
+	var results = make([][]string, 4)
+	var errs = make(chan error, 4)
+	for i := range results {
+		go func(i int) {
+			var err error
+			results[i], err = loader.Load(context.Background(), i%3+1)
+			errs <- err
+		}(i)
+	}
+	for range results {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(results[0]) != 2 || len(results[1]) != 0 || len(results[2]) != 1 || len(results[3]) != 2 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 1 || calls[0].SQL != "SELECT author_id, title FROM posts WHERE author_id IN ?" || len(calls[0].Args[0].([]int)) != 3 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+
+	if _, err := loader.Load(context.Background(), 2); err != nil || len(exec.Calls()) != 1 {
+		t.Fatalf("loaded key fetched again: %v", err)
+	}
+
+	var invalid = query.NewLoader(context.Background(), func() *query.Builder {
+		return query.NewWithExecutor(exec, "SELECT author_id, title FROM posts")
+	}, "author_id) OR (1 = 1", func(rows *sql.Rows) (int, string, error) {
+		return 0, "", nil
+	})
+	if _, err := invalid.Load(context.Background(), 1); !errors.Is(err, query.ErrInvalidColumn) {
+		t.Fatalf("expected ErrInvalidColumn, got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM tickets", []string{"id", "status"}, []interface{}{2, "open"}).
+		Count(3)
+
+	var handler = query.Handler(func(r *http.Request) *query.Builder {
+		return query.NewWithExecutor(exec, "SELECT id, status FROM tickets")
+	}, query.HandlerPolicy{
+		Sortable: []string{"id", "created_at"},
+		Filters:  map[string]string{"status": "status = ?"},
+		MaxLimit: 50,
+		Envelope: "data",
+	})
+
+	var w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tickets?page=2&limit=500&sort=-created_at,id&status=open", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Data query.Pagination `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.Page != 2 || body.Data.PerPage != 50 || body.Data.TotalRecord != 3 {
+		t.Fatalf("unexpected pagination: %s", w.Body)
+	}
+
+	var found bool
+	for _, call := range exec.Calls() {
+		found = found || call.SQL == "SELECT id, status FROM tickets WHERE status = ? ORDER BY created_at DESC,id ASC LIMIT 50 OFFSET 50"
+	}
+	if !found {
+		t.Fatalf("unexpected calls: %v", exec.Calls())
+	}
+
+	for _, target := range []string{"/tickets?sort=password", "/tickets?page=0", "/tickets?limit=x"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: unexpected status %d", target, w.Code)
+		}
+	}
+}
+
+// parkedContext signal parked the first time a caller waits on it
+type parkedContext struct {
+	context.Context
+	once   sync.Once
+	parked chan<- struct{}
+}
+
+func (c *parkedContext) Done() <-chan struct{} {
+	c.once.Do(func() { c.parked <- struct{}{} })
+	return c.Context.Done()
+}
+
+func TestExecutorSingleflight(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM stats", []string{"id"}, []interface{}{1}).
+		Count(1)
+	var hold = exec.Hold("FROM stats")
+
+	var builder = func(key string) *query.Builder {
+		return query.NewWithExecutor(exec, "SELECT id FROM stats").
+			OrderBy("id").
+			Limit(10).
+			Singleflight(key)
+	}
+	var records = func(records interface{}) query.RowsFunc {
+		return func(rows *sql.Rows) (interface{}, error) {
+			return records, nil
+		}
+	}
+	var expect = func(pagination *query.Pagination, err error, records interface{}) error {
+		if err == nil && pagination.Records != records {
+			err = fmt.Errorf("unexpected records %v, expected %v", pagination.Records, records)
+		}
+		return err
+	}
+
+	var errs = make(chan error, 7)
+	go func() {
+		pagination, err := builder("stats").PagingRows(context.Background(), records("shared"))
+		errs <- expect(pagination, err, "shared")
+	}()
+	<-hold.Arrived()
+
+	// every caller below signals parked once waiting on the flight or the held query
+	var parked = make(chan struct{}, 6)
+	var parkedCtx = func() context.Context {
+		return &parkedContext{Context: context.Background(), parked: parked}
+	}
+	for i := 0; i < 4; i++ {
+		go func() {
+			pagination, err := builder("stats").PagingRows(parkedCtx(), records("own"))
+			errs <- expect(pagination, err, "shared")
+		}()
+	}
+	go func() {
+		pagination, err := builder("other").PagingRows(parkedCtx(), records("other"))
+		errs <- expect(pagination, err, "other")
+	}()
+	go func() {
+		pagination, err := builder("stats").PagingStream(parkedCtx(), func(row query.Row) error {
+			return nil
+		})
+		if err == nil {
+			if rows, ok := pagination.Records.([]query.Row); !ok || len(rows) != 1 {
+				err = fmt.Errorf("unexpected stream records %v", pagination.Records)
+			}
+		}
+		errs <- err
+	}()
+
+	for i := 0; i < 6; i++ {
+		<-parked
+	}
+	hold.Release()
+	for i := 0; i < 7; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls := exec.Calls(); len(calls) != 6 {
+		t.Fatalf("expected a data and count query per key and the stream, got %v", calls)
+	}
+}
+
+func TestExecutorPrefetch(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM products", []string{"id"}, []interface{}{1}).
+		Count(25)
+
+	query.SetCacheStore(query.NewMemoryCache(10))
+	defer query.SetCacheStore(query.NewMemoryCache(10000))
+
+	var paging = func(page int) {
+		_, err := query.NewWithExecutor(exec, "SELECT id FROM products").
+			OrderBy("id").
+			Limit(10).
+			Page(page).
+			Cached(time.Minute).
+			Prefetch().
+			PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+				var ids = []int{}
+				for rows.Next() {
+					var id int
+					rows.Scan(&id)
+					ids = append(ids, id)
+				}
+				return ids, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paging(1)
+	for deadline := time.Now().Add(time.Second); len(exec.Calls()) < 4; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("next page not prefetched: %v", exec.Calls())
+		}
+	}
+	if call := exec.LastCall(); call.SQL != "SELECT id FROM products ORDER BY id LIMIT 10 OFFSET 10" && !strings.Contains(call.SQL, "COUNT(1)") {
+		t.Fatalf("unexpected prefetch: %s", call.SQL)
+	}
+
+	paging(2)
+	for deadline := time.Now().Add(time.Second); len(exec.Calls()) < 6; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("page 3 not prefetched: %v", exec.Calls())
+		}
+	}
+	var page2 int
+	for _, call := range exec.Calls() {
+		if strings.HasSuffix(call.SQL, "OFFSET 10") {
+			page2++
+		}
+	}
+	if page2 != 1 {
+		t.Fatalf("page 2 should be served from the cache: %v", exec.Calls())
+	}
+}
+
+func TestExecutorTimeWindow(t *testing.T) {
+	var oldest = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	var newest = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	var exec = New(query.DialectPostgres).
+		Rows("FROM events", []string{"id", "created_at"}, []interface{}{1, newest}, []interface{}{3, oldest}, []interface{}{2, oldest}).
+		Count(40)
+
+	type event struct {
+		ID        int
+		CreatedAt *time.Time `gorm:"column:created_at"`
+	}
+
+	var until = time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)
+	pagination, err := query.NewWithExecutor(exec, "SELECT id, created_at FROM events").
+		WithTimeColumn("e.created_at", "e.id").
+		Until(until, nil).
+		Limit(3).
+		PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+			var events []event
+			for rows.Next() {
+				var e event
+				if err := rows.Scan(&e.ID, &e.CreatedAt); err != nil {
+					return nil, err
+				}
+				events = append(events, e)
+			}
+			return events, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var window = pagination.Window
+	if window == nil || !window.Oldest.Equal(oldest) || window.OldestID != 2 || !window.Newest.Equal(newest) || window.NewestID != 1 {
+		t.Fatalf("unexpected window: %+v", window)
+	}
+	if calls := exec.Calls(); len(calls) != 2 {
+		t.Fatalf("the window should come from the records: %v", calls)
+	} else if !strings.Contains(calls[0].SQL, "e.created_at < ?") || !strings.Contains(calls[0].SQL, "ORDER BY e.created_at DESC,e.id DESC") {
+		t.Fatalf("unexpected window query: %s", calls[0].SQL)
+	}
+
+	// the rows tied with the boundary timestamp are kept by its id
+	exec.Reset()
+	pagination, err = query.NewWithExecutor(exec, "SELECT id, created_at FROM events").
+		WithTimeColumn("created_at", "id").
+		Since(window.Oldest, window.OldestID).
+		PagingStream(context.Background(), func(row query.Row) error {
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pagination.Window == nil || !pagination.Window.Oldest.Equal(oldest) || pagination.Window.OldestID != int64(2) {
+		t.Fatalf("unexpected window of rows: %+v", pagination.Window)
+	}
+	var call = exec.Calls()[0]
+	if !strings.Contains(call.SQL, "(created_at, id) > (?, ?)") || !strings.Contains(call.SQL, "ORDER BY created_at ASC,id ASC") {
+		t.Fatalf("unexpected window query: %s", call.SQL)
+	}
+	if len(call.Args) != 2 || call.Args[1] != 2 {
+		t.Fatalf("unexpected window args: %v", call.Args)
+	}
+
+	if _, err := query.NewWithExecutor(exec, "SELECT id FROM events").Since(until, nil).PagingRows(context.Background(), nil); !errors.Is(err, query.ErrNoTimeColumn) {
+		t.Fatalf("expected ErrNoTimeColumn, got %v", err)
+	}
+	if _, err := query.NewWithExecutor(exec, "SELECT id FROM events").WithTimeColumn("created_at", "id;").PagingRows(context.Background(), nil); !errors.Is(err, query.ErrInvalidColumn) {
+		t.Fatalf("expected ErrInvalidColumn, got %v", err)
+	}
+}
+
+func TestExecutorSingleRoundTrip(t *testing.T) {
+	var exec = New(query.DialectMySQL).
+		Rows("FROM users", []string{"id"}, []interface{}{1}, []interface{}{2}).
+		Count(12)
+
+	pagination, err := query.NewWithExecutor(exec, "SELECT id FROM users").
+		Where("active = ?", true).
+		OrderBy("id").
+		Limit(2).
+		SingleRoundTrip().
+		PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+			var ids = []int{}
+			for rows.Next() {
+				var id int
+				if err := rows.Scan(&id); err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			return ids, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pagination.TotalRecord != 12 || len(pagination.Records.([]int)) != 2 {
+		t.Fatalf("unexpected pagination: %+v", pagination)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 1 || calls[0].SQL != "SELECT COUNT(1) FROM (SELECT id FROM users WHERE active = ?) t; SELECT id FROM users WHERE active = ? ORDER BY id LIMIT 2 OFFSET 0" || len(calls[0].Args) != 2 {
+		t.Fatalf("expected a single batch, got %v", calls)
+	}
+
+	// the total and records come from two result sets, a failing data statement fails the batch
+	exec.Error("FROM users WHERE active = ? ORDER BY", errors.New("data failed"))
+	_, err = query.NewWithExecutor(exec, "SELECT id FROM users").
+		Where("active = ?", true).
+		OrderBy("id").
+		SingleRoundTrip().
+		PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+			return nil, nil
+		})
+	if err == nil || err.Error() != "data failed" {
+		t.Fatalf("expected the data statement error, got %v", err)
+	}
+}
+
+func TestExecutorDegraded(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM products", []string{"id"}, []interface{}{1}).
+		Count(1)
+
+	query.SetCacheStore(query.NewMemoryCache(10))
+	defer query.SetCacheStore(query.NewMemoryCache(10000))
+
+	var paging = func() (*query.Pagination, error) {
+		return query.NewWithExecutor(exec, "SELECT id FROM products").
+			OrderBy("id").
+			Cached(time.Minute, "products").
+			Degraded(time.Hour).
+			PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+				var ids = []int{}
+				for rows.Next() {
+					var id int
+					rows.Scan(&id)
+					ids = append(ids, id)
+				}
+				return ids, nil
+			})
+	}
+
+	if _, err := paging(); err != nil {
+		t.Fatal(err)
+	}
+
+	var down = errors.New("connection refused")
+	exec.Error("FROM products", down)
+	query.InvalidateCache(context.Background(), "products")
+
+	pagination, err := paging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata, ok := pagination.Metadata.(map[string]interface{}); !ok || metadata["stale"] != true || pagination.TotalRecord != 1 {
+		t.Fatalf("expected a stale page, got %+v", pagination)
+	}
+
+	exec.Error("SELECT 1", down)
+	if err := query.HealthCheck(context.Background(), exec); !errors.Is(err, down) {
+		t.Fatalf("expected the health check to fail, got %v", err)
+	}
+	var calls = len(exec.Calls())
+	if _, err := paging(); err != nil || len(exec.Calls()) != calls {
+		t.Fatalf("unhealthy database queried: %v", err)
+	}
+
+	exec.Rows("SELECT 1", []string{"one"}, []interface{}{1})
+	if err := query.HealthCheck(context.Background(), exec); err != nil {
+		t.Fatal(err)
+	}
+	calls = len(exec.Calls())
+	if _, err := paging(); err != nil || len(exec.Calls()) == calls {
+		t.Fatalf("healthy database not queried: %v", err)
+	}
+}
+
+func TestExecutorWatch(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("FROM orders", []string{"id"}, []interface{}{1}).
+		Count(1)
+
+	var listener = query.NewListener("")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pages, err := query.NewWithExecutor(exec, "SELECT id FROM orders").
+		OrderBy("id").
+		WithListener(listener).
+		Watch(ctx, "orders_changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var next = func() *query.Pagination {
+		select {
+		case pagination := <-pages:
+			return pagination
+		case <-time.After(time.Second):
+			t.Fatal("no page pushed")
+			return nil
+		}
+	}
+
+	if pagination := next(); pagination.TotalRecord != 1 {
+		t.Fatalf("unexpected first page: %+v", pagination)
+	}
+
+	exec.Rows("FROM orders", []string{"id"}, []interface{}{1}, []interface{}{2}).Count(2)
+	listener.Notify("orders_changed", "")
+	if pagination := next(); pagination.TotalRecord != 2 || len(pagination.Records.([]query.Row)) != 2 {
+		t.Fatalf("unexpected page after notify: %+v", pagination)
+	}
+
+	exec.Error("FROM orders", errors.New("orders failed"))
+	listener.Notify("orders_changed", "")
+	if pagination := next(); pagination.Err == nil || pagination.Err.Error() != "orders failed" {
+		t.Fatalf("expected the failed execution, got %+v", pagination)
+	}
+
+	cancel()
+	for range pages {
+	}
+
+	if _, err := query.NewWithExecutor(exec, "SELECT id FROM orders").WithListener(listener).Watch(context.Background(), "orders_changed"); err == nil {
+		t.Fatal("expected the first page error")
+	}
+	if _, err := query.NewWithExecutor(exec, "SELECT id FROM orders").Watch(context.Background(), "orders_changed"); !errors.Is(err, query.ErrNoListener) {
+		t.Fatalf("expected ErrNoListener, got %v", err)
+	}
+}
+
+// mysqlDialector gorm on the fake driver reporting mysql
+type mysqlDialector struct {
+	gorm.Dialector
+}
+
+func (mysqlDialector) Name() string {
+	return query.DialectMySQL
+}
+
+func TestAdvisoryLock(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Rows("pg_advisory_xact_lock", []string{"pg_advisory_xact_lock"}, []interface{}{""})
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: exec.DB()}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	var called bool
+	if err := query.WithAdvisoryLock(context.Background(), gdb, "report", func(tx *gorm.DB) error {
+		called = true
+		return nil
+	}); err != nil || !called {
+		t.Fatalf("lock not run: %v", err)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 1 || calls[0].SQL != "SELECT pg_advisory_xact_lock(hashtext($1))" || calls[0].Args[0] != "report" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
+
+func TestAdvisoryLockMySQL(t *testing.T) {
+	var exec = New(query.DialectMySQL).
+		Rows("GET_LOCK", []string{"lock"}, []interface{}{int64(1)}).
+		Rows("RELEASE_LOCK", []string{"release"}, []interface{}{int64(1)})
+
+	gdb, err := gorm.Open(mysqlDialector{postgres.New(postgres.Config{Conn: exec.DB()})}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	var failed = errors.New("failed")
+	if err := query.WithAdvisoryLock(context.Background(), gdb, "report", func(tx *gorm.DB) error {
+		return failed
+	}); !errors.Is(err, failed) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 2 || !strings.Contains(calls[0].SQL, "GET_LOCK(") || !strings.Contains(calls[1].SQL, "RELEASE_LOCK(") || calls[1].Args[0] != "report" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+
+	exec.Rows("GET_LOCK", []string{"lock"}, []interface{}{int64(0)})
+	if err := query.WithAdvisoryLock(context.Background(), gdb, "report", func(tx *gorm.DB) error {
+		return nil
+	}); !errors.Is(err, query.ErrLockNotAcquired) {
+		t.Fatalf("expected ErrLockNotAcquired, got %v", err)
+	}
+}
+
+func TestIntoTemp(t *testing.T) {
+	var ctx = context.Background()
+	var exec = New(query.DialectPostgres).
+		Exec("set_config", 0).
+		Exec("CREATE TEMPORARY TABLE", 2).
+		Rows("FROM active_users", []string{"id"}, []interface{}{2}, []interface{}{3})
+
+	var b = query.NewWithExecutor(exec, "SELECT id FROM users").WithSchema("tenant_a").Where("id > ?", 1)
+	if err := b.IntoTemp(ctx, exec, "active_users; DROP TABLE users"); !errors.Is(err, query.ErrInvalidTable) {
+		t.Fatalf("expected ErrInvalidTable, got %v", err)
+	}
+
+	var err = exec.Transaction(ctx, func(tx query.Executor) error {
+		if err := b.IntoTemp(ctx, tx, "active_users"); err != nil {
+			return err
+		}
+
+		var ids []int
+		return b.Chain(tx, "SELECT id FROM active_users").ReadRows(ctx, func(rows *sql.Rows) error {
+			for rows.Next() {
+				var id int
+				if err := rows.Scan(&id); err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+			if len(ids) != 2 {
+				t.Fatalf("unexpected ids %v", ids)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("into temp: %v", err)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 3 || calls[0].Args[0] != `"tenant_a"` ||
+		calls[1].SQL != "CREATE TEMPORARY TABLE active_users AS SELECT id FROM users WHERE id > ?" || calls[1].Args[0] != 1 ||
+		!strings.Contains(calls[2].SQL, "FROM active_users") {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var ctx = context.Background()
+	var exec = New(query.DialectPostgres).
+		Exec("set_config", 0).
+		Rows("EXPLAIN", []string{"QUERY PLAN"}, []interface{}{`[{"Plan": {"Node Type": "Seq Scan", "Total Cost": 12.5, "Plan Rows": 40}}]`})
+
+	plan, err := query.NewWithExecutor(exec, "SELECT * FROM users").WithSchema("tenant_a").Explain(ctx, false)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if plan.TotalCost != 12.5 || plan.EstimatedRows != 40 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) != 2 || !strings.Contains(calls[0].SQL, "set_config") || !strings.HasPrefix(calls[1].SQL, "EXPLAIN (FORMAT JSON) SELECT") {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+
+	exec.Reset()
+	if _, err := query.NewWithExecutor(exec, "DELETE FROM users RETURNING id").ReadOnly().Explain(ctx, true); !errors.Is(err, query.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if len(exec.Calls()) != 0 {
+		t.Fatalf("analyze should not run: %+v", exec.Calls())
+	}
+
+	var mysql = New(query.DialectMySQL).
+		Rows("EXPLAIN", []string{"EXPLAIN"}, []interface{}{`{"query_block": {"cost_info": {"query_cost": "8.25"}, "ordering_operation": {"nested_loop": [
+			{"table": {"table_name": "o", "rows_produced_per_join": 200}},
+			{"table": {"table_name": "u", "rows_produced_per_join": 150}}]}}}`})
+	plan, err = query.NewWithExecutor(mysql, "SELECT * FROM orders o JOIN users u ON u.id = o.user_id ORDER BY o.id").Explain(ctx, false)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if plan.TotalCost != 8.25 || plan.EstimatedRows != 150 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+}
+
+func TestExecutorMaxCost(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Exec("set_config", 0).
+		Rows("EXPLAIN", []string{"QUERY PLAN"}, []interface{}{`[{"Plan": {"Node Type": "Seq Scan", "Total Cost": 5000, "Plan Rows": 40}}]`})
+
+	var _, err = query.NewWithExecutor(exec, "SELECT * FROM users").OrderBy("id").WithSchema("tenant_a").WithMaxCost(1000).
+		PagingRows(context.Background(), func(rows *sql.Rows) (interface{}, error) {
+			return nil, nil
+		})
+	if !errors.Is(err, query.ErrQueryTooExpensive) {
+		t.Fatalf("expected ErrQueryTooExpensive, got %v", err)
+	}
+
+	var calls = exec.Calls()
+	if len(calls) < 2 || !strings.Contains(calls[0].SQL, "set_config") || !strings.HasPrefix(calls[1].SQL, "EXPLAIN") {
+		t.Fatalf("expected the explain in the schema session, got %+v", calls)
+	}
+}
+
+func TestExecutorPagingShards(t *testing.T) {
+	var shard = func(ids ...int) *Executor {
+		var rows = [][]interface{}{}
+		for _, id := range ids {
+			rows = append(rows, []interface{}{id})
+		}
+		return New(query.DialectPostgres).
+			Exec("SET TRANSACTION READ ONLY", 0).
+			Exec("set_config", 0).
+			Count(len(ids)).
+			Rows("FROM users", []string{"id"}, rows...)
+	}
+	var a, b = shard(1, 4), shard(2, 3)
+
+	pagination, err := query.New(nil, "SELECT id FROM users").WithDialect(query.DialectPostgres).
+		OrderBy("id").Limit(3).WithSchema("tenant_a").ReadOnlyTx().
+		PagingShards(context.Background(), a, b)
+	if err != nil {
+		t.Fatalf("paging shards: %v", err)
+	}
+
+	var records = pagination.Records.([]query.Row)
+	if pagination.TotalRecord != 4 || len(records) != 3 || records[2]["id"] != int64(3) {
+		t.Fatalf("unexpected pagination %+v", pagination)
+	}
+
+	for _, exec := range []*Executor{a, b} {
+		var calls = exec.Calls()
+		if len(calls) != 4 || calls[0].SQL != "SET TRANSACTION READ ONLY" || calls[1].Args[0] != `"tenant_a"` {
+			t.Fatalf("expected the shard session, got %+v", calls)
+		}
+	}
+}
+
+func TestExecutorCachedSchemas(t *testing.T) {
+	var exec = New(query.DialectPostgres).
+		Exec("set_config", 0).
+		Count(1)
+
+	query.SetCacheStore(query.NewMemoryCache(10))
+	defer query.SetCacheStore(query.NewMemoryCache(10000))
+
+	var paging = func(ctx context.Context, id int) string {
+		if id > 0 {
+			exec.Rows("FROM products", []string{"id"}, []interface{}{id})
+		}
+		pagination, err := query.NewWithExecutor(exec, "SELECT id FROM products").
+			Cached(time.Minute, "products").
+			Degraded(time.Minute).
+			PagingRows(ctx, func(rows *sql.Rows) (interface{}, error) {
+				var ids = []int{}
+				for rows.Next() {
+					var id int
+					rows.Scan(&id)
+					ids = append(ids, id)
+				}
+				return ids, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(pagination.Records)
+		return string(data)
+	}
+
+	var tenantA = query.ContextWithSchema(context.Background(), "tenant_a")
+	var tenantB = query.ContextWithSchema(context.Background(), "tenant_b")
+	if records := paging(tenantA, 1); records != "[1]" {
+		t.Fatalf("unexpected records of tenant_a: %s", records)
+	}
+	if records := paging(tenantB, 2); records != "[2]" {
+		t.Fatalf("tenant_b read the page of tenant_a: %s", records)
+	}
+	if records := paging(tenantA, 3); records != "[1]" {
+		t.Fatalf("tenant_a page should be cached: %s", records)
+	}
+
+	// the stale pages are per tenant too
+	if err := query.InvalidateCache(context.Background(), "products"); err != nil {
+		t.Fatal(err)
+	}
+	exec.Error("FROM products", errors.New("connection refused"))
+	if records := paging(tenantB, 0); records != "[2]" {
+		t.Fatalf("unexpected stale page of tenant_b: %s", records)
+	}
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRandomNotSupported returned when ordering randomly with a seed on a dialect without seeded random
+var ErrRandomNotSupported = errors.New("query: seeded random order is not supported for this dialect")
+
+// OrderRandom append an order stable for the seed, by the md5 of the key column, so pages don't repeat rows
+func (b *Builder) OrderRandom(seed int64, key string) *Builder {
+	if !isIdentifier(key) {
+		b.fail(fmt.Errorf("%w: column %q", ErrInvalidOrder, key))
+		return b
+	}
+
+	b.addSort(sortTerm{expr: key, random: true}, seed)
+	return b
+}
+
+// randomOrder render the seeded order of the key, the seed is bound to the single placeholder
+func randomOrder(dialect string, key string) string {
+	if dialect == DialectMySQL {
+		return fmt.Sprintf("MD5(CONCAT(%s, ?)),%s", key, key)
+	}
+	return fmt.Sprintf("md5(%s::text || ?::text),%s", key, key)
+}
+
+// checkRandom reject seeded random orders on dialects other than postgres and MySQL
+func (b *Builder) checkRandom() error {
+	var dialect = b.dialect()
+	if dialect == DialectPostgres || dialect == DialectMySQL {
+		return nil
+	}
+	for _, sort := range b.sorts {
+		if sort.random {
+			return fmt.Errorf("%w: %s", ErrRandomNotSupported, dialect)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderRandom(t *testing.T) {
+	var tests = []struct {
+		dialect string
+		want    string
+	}{
+		{DialectPostgres, "SELECT * FROM posts ORDER BY md5(p.id::text || ?::text),p.id LIMIT 10 OFFSET 10"},
+		{DialectMySQL, "SELECT * FROM posts ORDER BY MD5(CONCAT(p.id, ?)),p.id LIMIT 10 OFFSET 10"},
+	}
+
+	for _, test := range tests {
+		var b = New(nil, "SELECT * FROM posts").
+			Where("published = ?", true).
+			OrderRandom(42, "p.id").
+			WithDialect(test.dialect).
+			Limit(10).
+			Page(2)
+		var queryString, _ = b.build()
+		if want := "SELECT * FROM posts WHERE published = ?" + test.want[len("SELECT * FROM posts"):]; queryString != want {
+			t.Errorf("got %s, want %s", queryString, want)
+		}
+		if values := b.values(); len(values) != 2 || values[1] != int64(42) {
+			t.Errorf("unexpected values: %v", values)
+		}
+		if err := b.validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM posts").OrderRandom(42, "id").WithDialect(DialectSQLite)
+	if err := b.validate(); !errors.Is(err, ErrRandomNotSupported) {
+		t.Fatalf("expected ErrRandomNotSupported, got %v", err)
+	}
+
+	b = New(nil, "SELECT * FROM posts").OrderRandom(42, "random()").WithDialect(DialectPostgres)
+	if err := b.validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Range bounds, [ and ] are inclusive, ( and ) exclusive
+const (
+	RangeInclusiveExclusive = "[)"
+	RangeInclusive          = "[]"
+	RangeExclusive          = "()"
+)
+
+// WhereRangeContains filter rows whose range column contains value (@>)
+func (b *Builder) WhereRangeContains(column string, value interface{}) *Builder {
+	if t, ok := value.(time.Time); ok {
+		return b.Where(fmt.Sprintf("%s @> ?::timestamptz", column), t)
+	}
+
+	return b.Where(fmt.Sprintf("%s @> ?", column), value)
+}
+
+// WhereRangeOverlaps filter rows whose tstzrange column overlaps [from, to) (&&)
+func (b *Builder) WhereRangeOverlaps(column string, from time.Time, to time.Time) *Builder {
+	return b.WhereRangeOverlapsBounds(column, from, to, RangeInclusiveExclusive)
+}
+
+// WhereRangeOverlapsBounds like WhereRangeOverlaps with explicit bounds, e.g. RangeInclusive
+func (b *Builder) WhereRangeOverlapsBounds(column string, from time.Time, to time.Time, bounds string) *Builder {
+	return b.Where(fmt.Sprintf("%s && tstzrange(?::timestamptz, ?::timestamptz, ?)", column), rangeBound(from), rangeBound(to), bounds)
+}
+
+// rangeBound bind zero times as NULL, which is an unbounded side
+func rangeBound(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
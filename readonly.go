@@ -0,0 +1,104 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrReadOnly returned when a read-only builder would run a statement writing data or schema
+var ErrReadOnly = errors.New("query: statement not allowed on a read-only builder")
+
+// ErrReadOnlyNotSupported returned when a read-only transaction can't be opened on the dialect or executor
+var ErrReadOnlyNotSupported = errors.New("query: read-only transactions are not supported")
+
+// ErrReadOnlySession returned by Rows and IntoTemp on ReadOnlyTx builders
+var ErrReadOnlySession = errors.New("query: read-only transaction requires a session, use PagingRows, Count or ScanRow")
+
+var readOnlyDefault = false
+
+// writeKeywords statements and clauses changing data, schema or locks
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+	"GRANT": true, "REVOKE": true, "COPY": true, "CALL": true, "DO": true, "LOCK": true,
+	"VACUUM": true, "REINDEX": true, "CLUSTER": true, "REFRESH": true, "INTO": true,
+}
+
+// SetReadOnlyDefault make every builder read-only, e.g. in report services wired to user input
+func SetReadOnlyDefault(readOnly bool) {
+	readOnlyDefault = readOnly
+}
+
+// ReadOnly refuse to run statements writing data or schema
+func (b *Builder) ReadOnly() *Builder {
+	b.readOnly = true
+	return b
+}
+
+// ReadOnlyTx also run the queries in a READ ONLY transaction, so the database enforces it (postgres only)
+func (b *Builder) ReadOnlyTx() *Builder {
+	b.readOnly = true
+	b.readOnlyTx = true
+	return b
+}
+
+// checkReadOnly reject write keywords in the statements of read-only builders
+func (b *Builder) checkReadOnly(statements ...statement) error {
+	if !b.readOnly && !readOnlyDefault {
+		return nil
+	}
+
+	for _, stmt := range statements {
+		if keyword := findWriteKeyword(stmt.sql); keyword != "" {
+			return fmt.Errorf("%w: %s", ErrReadOnly, keyword)
+		}
+	}
+
+	return nil
+}
+
+// findWriteKeyword first write keyword outside literals and comments, empty if none
+func findWriteKeyword(sql string) string {
+	var keyword string
+	scanWords(sql, func(word string) bool {
+		if writeKeywords[strings.ToUpper(word)] {
+			keyword = strings.ToUpper(word)
+			return false
+		}
+		return true
+	})
+
+	return keyword
+}
+
+// scanWords call fn with each word outside literals, quoted identifiers and comments until it returns false
+func scanWords(sql string, fn func(word string) bool) {
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(sql) && sql[i] != c; i++ {
+			}
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case isIdentChar(c):
+			var start = i
+			for i+1 < len(sql) && isIdentChar(sql[i+1]) {
+				i++
+			}
+			if !fn(sql[start : i+1]) {
+				return
+			}
+		}
+	}
+}
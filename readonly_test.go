@@ -0,0 +1,33 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	var tests = []struct {
+		sql     string
+		keyword string
+	}{
+		{"SELECT * FROM users WHERE note = 'delete me' -- update later", ""},
+		{"SELECT \"update\", updated_at FROM users /* INSERT */", ""},
+		{"WITH gone AS (DELETE FROM users RETURNING *) SELECT * FROM gone", "DELETE"},
+		{"SELECT * INTO backup FROM users", "INTO"},
+		{"SELECT * FROM users FOR UPDATE", "UPDATE"},
+	}
+
+	for _, test := range tests {
+		var err = New(nil, test.sql).ReadOnly().checkReadOnly(statement{sql: test.sql})
+		if test.keyword == "" && err != nil || test.keyword != "" && !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: got %v", test.sql, err)
+		}
+		if got := findWriteKeyword(test.sql); got != test.keyword {
+			t.Errorf("%s: got keyword %q, want %q", test.sql, got, test.keyword)
+		}
+	}
+
+	if err := New(nil, "").checkReadOnly(statement{sql: "DELETE FROM users"}); err != nil {
+		t.Fatalf("builders aren't read-only by default: %v", err)
+	}
+}
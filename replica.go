@@ -0,0 +1,114 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+)
+
+// ReplicaRouter executor sending writes to the primary and reads of replica builders to read replicas
+type ReplicaRouter struct {
+	primary  Executor
+	replicas []Executor
+	auto     bool
+	next     uint32
+}
+
+type stickyReplicaKey struct{}
+
+type stickyReplica struct {
+	once  sync.Once
+	index int
+}
+
+// NewReplicaRouter init a router, builders read from replicas with UseReplica
+func NewReplicaRouter(primary Executor, replicas ...Executor) *ReplicaRouter {
+	return &ReplicaRouter{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+// WithAutoReplica route reads of every builder to replicas unless the builder calls UsePrimary
+func (r *ReplicaRouter) WithAutoReplica(auto bool) *ReplicaRouter {
+	r.auto = auto
+	return r
+}
+
+// WithStickyReplica make the replica reads of the returned context use the same replica
+func WithStickyReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyReplicaKey{}, &stickyReplica{})
+}
+
+// Replica pick a replica, round robin or sticky per context. Without replicas the primary is used.
+func (r *ReplicaRouter) Replica(ctx context.Context) Executor {
+	if len(r.replicas) == 0 {
+		return r.primary
+	}
+
+	if sticky, ok := ctx.Value(stickyReplicaKey{}).(*stickyReplica); ok {
+		sticky.once.Do(func() {
+			sticky.index = r.pick()
+		})
+		return r.replicas[sticky.index]
+	}
+
+	return r.replicas[r.pick()]
+}
+
+func (r *ReplicaRouter) pick() int {
+	return int((atomic.AddUint32(&r.next, 1) - 1) % uint32(len(r.replicas)))
+}
+
+// QueryContext ...
+func (r *ReplicaRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext ...
+func (r *ReplicaRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext ...
+func (r *ReplicaRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+// Dialect ...
+func (r *ReplicaRouter) Dialect() string {
+	return r.primary.Dialect()
+}
+
+// UseReplica read from a replica when the executor is a ReplicaRouter
+func (b *Builder) UseReplica() *Builder {
+	b.replica = replicaRead
+	return b
+}
+
+// UsePrimary read from the primary even when the router routes reads to replicas automatically
+func (b *Builder) UsePrimary() *Builder {
+	b.replica = primaryRead
+	return b
+}
+
+const (
+	replicaRead = 1
+	primaryRead = -1
+)
+
+// reader executor for the read queries of one execution, the same replica serves count and data
+func (b *Builder) reader(ctx context.Context) Executor {
+	var exec = b.executor()
+	router, ok := exec.(*ReplicaRouter)
+	if !ok {
+		return exec
+	}
+
+	if b.replica == replicaRead || (router.auto && b.replica != primaryRead) {
+		return router.Replica(ctx)
+	}
+
+	return exec
+}
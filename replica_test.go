@@ -0,0 +1,36 @@
+package query
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReplicaRouter(t *testing.T) {
+	var primary = NewSQLExecutor(nil, DialectPostgres)
+	var replicas = []Executor{NewSQLExecutor(nil, DialectPostgres), NewSQLExecutor(nil, DialectPostgres)}
+	var router = NewReplicaRouter(primary, replicas...)
+
+	var b = NewWithExecutor(router, "SELECT * FROM users")
+	if b.reader(context.Background()) != router {
+		t.Fatalf("reads should go to the primary by default")
+	}
+
+	b.UseReplica()
+	if first, second := b.reader(context.Background()), b.reader(context.Background()); first == second {
+		t.Fatalf("replicas should be picked round robin")
+	}
+
+	var ctx = WithStickyReplica(context.Background())
+	if first, second := b.reader(ctx), b.reader(ctx); first != second {
+		t.Fatalf("sticky context should keep the same replica")
+	}
+
+	router.WithAutoReplica(true)
+	var auto = NewWithExecutor(router, "SELECT * FROM users")
+	if auto.reader(ctx) == router {
+		t.Fatalf("auto replica should read from a replica")
+	}
+	if auto.UsePrimary().reader(ctx) != router {
+		t.Fatalf("UsePrimary should read from the primary")
+	}
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"errors"
+)
+
+// SingleRoundTrip send the count and data queries as one batch on MySQL and SQL Server, see PagingPgx on postgres.
+// MySQL needs multiStatements=true and interpolateParams=true in the DSN, which let injected SQL stack statements.
+func (b *Builder) SingleRoundTrip() *Builder {
+	b.roundTrip = true
+	return b
+}
+
+// batched report whether the count and data queries go in one batch on the dialect
+func (b *Builder) batched() bool {
+	if !b.roundTrip {
+		return false
+	}
+
+	var dialect = b.dialect()
+	return dialect == DialectMySQL || dialect == DialectSQLServer
+}
+
+// batchedPaging run the count then the data statement in one batch, reading both result sets
+func (b *Builder) batchedPaging(ctx context.Context, exec Executor, stmt statement, countStmt statement, f RowsFunc) (count int, summary map[string]interface{}, result interface{}, err error) {
+	var values = make([]interface{}, 0, len(countStmt.values)+len(stmt.values))
+	values = append(values, countStmt.values...)
+	values = append(values, stmt.values...)
+
+	rows, err := exec.QueryContext(ctx, countStmt.sql+"; "+stmt.sql, values...)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer rows.Close()
+
+	if count, summary, err = scanSummaryRow(rows); err != nil {
+		return 0, nil, nil, err
+	}
+	if !rows.NextResultSet() {
+		if err = rows.Err(); err == nil {
+			err = errors.New("query: batch returned no data result set")
+		}
+		return 0, nil, nil, err
+	}
+
+	if result, err = f(rows); err != nil {
+		return 0, nil, nil, err
+	}
+
+	return count, summary, result, rows.Err()
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SampleMethod TABLESAMPLE method
+type SampleMethod string
+
+// Sample methods
+const (
+	SampleSystem    SampleMethod = "SYSTEM"
+	SampleBernoulli SampleMethod = "BERNOULLI"
+)
+
+type sample struct {
+	method  SampleMethod
+	percent float64
+}
+
+var sampleFromRegexp = regexp.MustCompile(`(?i)^FROM\s+([A-Za-z_][\w.]*)(\s+(?:AS\s+)?([A-Za-z_]\w*))?`)
+
+var sampleAliasKeywords = map[string]bool{
+	"WHERE": true, "JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "FULL": true,
+	"CROSS": true, "NATURAL": true, "ON": true, "GROUP": true, "ORDER": true, "LIMIT": true,
+	"OFFSET": true, "HAVING": true, "WINDOW": true, "UNION": true, "TABLESAMPLE": true,
+	"WITH": true, "USE": true, "FORCE": true, "IGNORE": true,
+}
+
+// Sample read a percentage of the first table in FROM with TABLESAMPLE
+func (b *Builder) Sample(method SampleMethod, percent float64) *Builder {
+	b.sample = &sample{
+		method:  method,
+		percent: percent,
+	}
+	return b
+}
+
+// applySample insert the TABLESAMPLE clause after the first table reference
+func (b *Builder) applySample(rawSQL string) string {
+	if b.sample == nil {
+		return rawSQL
+	}
+
+	var clause string
+	switch b.dialect() {
+	case DialectPostgres:
+		clause = fmt.Sprintf("TABLESAMPLE %s (%v)", b.sample.method, b.sample.percent)
+	case DialectSQLServer:
+		clause = fmt.Sprintf("TABLESAMPLE SYSTEM (%v PERCENT)", b.sample.percent)
+	default:
+		return rawSQL
+	}
+
+	at, _, ok := firstTable(rawSQL)
+	if !ok {
+		return rawSQL
+	}
+
+	return fmt.Sprintf("%s %s%s", rawSQL[:at], clause, rawSQL[at:])
+}
+
+// firstTable end of the first table reference in FROM, and the name it is referenced by (alias or table)
+func firstTable(rawSQL string) (at int, name string, ok bool) {
+	// the top-level FROM, not EXTRACT(... FROM ...) or a subquery of the select list
+	var from = indexTopLevelKeyword(rawSQL, "FROM")
+	if from < 0 {
+		return 0, "", false
+	}
+
+	var loc = sampleFromRegexp.FindStringSubmatchIndex(rawSQL[from:])
+	if loc == nil {
+		return 0, "", false
+	}
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += from
+		}
+	}
+
+	// end after the alias, or after the table name when the next word is a keyword
+	if loc[6] >= 0 && !sampleAliasKeywords[strings.ToUpper(rawSQL[loc[6]:loc[7]])] {
+		return loc[1], rawSQL[loc[6]:loc[7]], true
+	}
+
+	return loc[3], rawSQL[loc[2]:loc[3]], true
+}
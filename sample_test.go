@@ -0,0 +1,49 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	var tests = []struct {
+		rawSQL string
+		want   string
+	}{
+		{
+			rawSQL: "SELECT u.* FROM users u LEFT JOIN profiles p ON p.id = u.profile_id",
+			want:   "SELECT u.* FROM users u TABLESAMPLE SYSTEM (10) LEFT JOIN profiles p ON p.id = u.profile_id",
+		},
+		{
+			rawSQL: "SELECT * FROM users WHERE id > 1",
+			want:   "SELECT * FROM users TABLESAMPLE SYSTEM (10) WHERE id > 1",
+		},
+		{
+			rawSQL: "SELECT * FROM public.users AS u",
+			want:   "SELECT * FROM public.users AS u TABLESAMPLE SYSTEM (10)",
+		},
+		{
+			rawSQL: "SELECT EXTRACT(YEAR FROM created_at) AS year FROM orders o",
+			want:   "SELECT EXTRACT(YEAR FROM created_at) AS year FROM orders o TABLESAMPLE SYSTEM (10)",
+		},
+		{
+			rawSQL: "SELECT u.id, (SELECT COUNT(1) FROM orders o WHERE o.user_id = u.id) AS orders FROM users u",
+			want:   "SELECT u.id, (SELECT COUNT(1) FROM orders o WHERE o.user_id = u.id) AS orders FROM users u TABLESAMPLE SYSTEM (10)",
+		},
+		{
+			rawSQL: "SELECT * FROM (SELECT * FROM users) u",
+			want:   "SELECT * FROM (SELECT * FROM users) u",
+		},
+	}
+
+	for _, test := range tests {
+		var b = New(nil, test.rawSQL).WithDialect(DialectPostgres).Sample(SampleSystem, 10)
+		if got := b.applySample(test.rawSQL); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM users").WithDialect(DialectMySQL).Sample(SampleSystem, 10)
+	if got := b.applySample("SELECT * FROM users"); got != "SELECT * FROM users" {
+		t.Errorf("sample should be ignored on mysql, got %s", got)
+	}
+}
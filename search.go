@@ -0,0 +1,70 @@
+package query
+
+import (
+	"fmt"
+)
+
+type textSearch struct {
+	column  string
+	config  string
+	tsquery string
+	args    []interface{}
+}
+
+// FullTextSearch filter rows whose tsvector column matches the query with websearch_to_tsquery
+func (b *Builder) FullTextSearch(column string, config string, query string) *Builder {
+	return b.fullTextSearch(column, "websearch_to_tsquery", config, query)
+}
+
+// FullTextSearchRaw like FullTextSearch, but the query uses to_tsquery operator syntax (&, |, !, :*)
+func (b *Builder) FullTextSearchRaw(column string, config string, query string) *Builder {
+	return b.fullTextSearch(column, "to_tsquery", config, query)
+}
+
+func (b *Builder) fullTextSearch(column string, fn string, config string, query string) *Builder {
+	var search = &textSearch{
+		column:  column,
+		config:  config,
+		tsquery: fmt.Sprintf("%s(?)", fn),
+		args:    []interface{}{query},
+	}
+	if config != "" {
+		search.tsquery = fmt.Sprintf("%s(?::regconfig, ?)", fn)
+		search.args = []interface{}{config, query}
+	}
+
+	b.search = search
+	return b.Where(fmt.Sprintf("%s @@ %s", column, search.tsquery), search.args...)
+}
+
+// WithSearchRank select ts_rank of the full text search as alias and order by it, best match first
+func (b *Builder) WithSearchRank(alias string) *Builder {
+	if b.search == nil {
+		return b
+	}
+
+	b.rankOrderBy = append(b.rankOrderBy, fmt.Sprintf("%s DESC", alias))
+	return b.AddSelect(fmt.Sprintf("ts_rank(%s, %s) AS %s", b.search.column, b.search.tsquery, alias), b.search.args...)
+}
+
+// WithSearchHighlight select ts_headline fragments of the matching document column as alias
+func (b *Builder) WithSearchHighlight(alias string, document string, options string) *Builder {
+	if b.search == nil {
+		return b
+	}
+
+	var args = []interface{}{}
+	var expr = document
+	if b.search.config != "" {
+		expr = fmt.Sprintf("?::regconfig, %s", document)
+		args = append(args, b.search.config)
+	}
+	expr = fmt.Sprintf("%s, %s", expr, b.search.tsquery)
+	args = append(args, b.search.args...)
+	if options != "" {
+		expr = fmt.Sprintf("%s, ?", expr)
+		args = append(args, options)
+	}
+
+	return b.AddSelect(fmt.Sprintf("ts_headline(%s) AS %s", expr, alias), args...)
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestFullTextSearchRank(t *testing.T) {
+	var b = New(nil, "SELECT p.id, p.title FROM posts p").
+		Where("p.published = ?", true).
+		FullTextSearch("p.search_vector", "english", `"go query" -orm`).
+		WithSearchRank("rank").
+		OrderBy("p.id DESC")
+
+	queryString, countQuery := b.build()
+	var want = "SELECT p.id, p.title, ts_rank(p.search_vector, websearch_to_tsquery(?::regconfig, ?)) AS rank FROM posts p WHERE p.published = ? AND p.search_vector @@ websearch_to_tsquery(?::regconfig, ?) ORDER BY rank DESC,p.id DESC"
+	if queryString != want {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if countQuery != "SELECT COUNT(1) FROM (SELECT p.id, p.title, ts_rank(p.search_vector, websearch_to_tsquery(?::regconfig, ?)) AS rank FROM posts p WHERE p.published = ? AND p.search_vector @@ websearch_to_tsquery(?::regconfig, ?)) t" {
+		t.Fatalf("unexpected count query: %s", countQuery)
+	}
+
+	var values = b.values()
+	if len(values) != 5 || values[0] != "english" || values[2] != true {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
+func TestFullTextSearchHighlight(t *testing.T) {
+	var b = New(nil, "SELECT p.id FROM posts p").
+		FullTextSearch("p.search_vector", "english", "query builder").
+		WithSearchHighlight("headline", "p.body", "MaxFragments=2")
+
+	queryString, _ := b.build()
+	var want = "SELECT p.id, ts_headline(?::regconfig, p.body, websearch_to_tsquery(?::regconfig, ?), ?) AS headline FROM posts p WHERE p.search_vector @@ websearch_to_tsquery(?::regconfig, ?)"
+	if queryString != want {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+
+	var values = b.values()
+	if len(values) != 6 || values[0] != "english" || values[2] != "query builder" || values[3] != "MaxFragments=2" || values[4] != "english" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	b = New(nil, "SELECT id FROM posts").WithSearchHighlight("headline", "body", "")
+	if queryString, _ := b.build(); queryString != "SELECT id FROM posts" {
+		t.Fatalf("unexpected query without search: %s", queryString)
+	}
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddSelect append an expression to the projection of the raw SQL, args are bound in place
+func (b *Builder) AddSelect(query string, args ...interface{}) *Builder {
+	b.selects = append(b.selects, query)
+	b.selectValues = append(b.selectValues, args...)
+	return b
+}
+
+// applySelects insert the extra select expressions before the top level FROM
+func (b *Builder) applySelects(rawSQL string) string {
+	if len(b.selects) == 0 {
+		return rawSQL
+	}
+
+	var at = indexTopLevelKeyword(rawSQL, "FROM")
+	if at < 0 {
+		return fmt.Sprintf("%s, %s", rawSQL, strings.Join(b.selects, ", "))
+	}
+
+	return fmt.Sprintf("%s, %s %s", strings.TrimRight(rawSQL[:at], " \t\r\n"), strings.Join(b.selects, ", "), rawSQL[at:])
+}
+
+// indexTopLevelKeyword index of the first keyword outside of parentheses and quotes, -1 if not found
+func indexTopLevelKeyword(sql string, keyword string) int {
+	var depth = 0
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		var c = sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && hasKeywordAt(sql, i, keyword):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// hasKeywordAt report whether keyword starts at i as a whole word, case insensitive
+func hasKeywordAt(sql string, i int, keyword string) bool {
+	if i+len(keyword) > len(sql) || !strings.EqualFold(sql[i:i+len(keyword)], keyword) {
+		return false
+	}
+	if i > 0 && isIdentChar(sql[i-1]) {
+		return false
+	}
+	if end := i + len(keyword); end < len(sql) && isIdentChar(sql[end]) {
+		return false
+	}
+
+	return true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,437 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Row column values of a row read by PagingShards
+type Row = map[string]interface{}
+
+type sortKey struct {
+	column     string
+	desc       bool
+	nullsFirst bool
+	bytewise   bool
+	numeric    bool
+}
+
+// PagingShards merge the pages of every shard by the ORDER BY, text needs COLLATE "C", deep pages cost more
+func (b *Builder) PagingShards(ctx context.Context, shards ...Executor) (*Pagination, error) {
+	if b.page < 1 {
+		b.page = 1
+	}
+
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	keys, err := parseSortKeys(b.buildOrderBy())
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var offset = (b.page - 1) * b.limit
+	var shard = *b
+	shard.page = 0
+	if b.limit > 0 {
+		shard.limit = offset + b.limit
+	}
+
+	sqlString, countSQLString := shard.build()
+	values, countValues := shard.boundValues()
+	if err := b.checkStatements(statement{sqlString, values}, statement{countSQLString, countValues}); err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var total int
+	var rows = []Row{}
+	var types = map[string]string{}
+	var firstErr error
+	for _, exec := range shards {
+		wg.Add(1)
+		go func(exec Executor) {
+			defer wg.Done()
+
+			// the checks, refresh, cost guard and session of the shard's connection
+			var sb = shard
+			sb.exec = exec
+
+			var count int
+			var shardRows []Row
+			var shardTypes map[string]string
+			var err = sb.beforeExec(ctx, statement{sqlString, values}, statement{countSQLString, countValues})
+			if err == nil {
+				err = sb.session(ctx, func(exec Executor, dedicated bool) error {
+					if err := exec.QueryRowContext(ctx, countSQLString, countValues...).Scan(&count); err != nil {
+						return err
+					}
+
+					var err error
+					shardRows, shardTypes, err = queryRows(ctx, exec, sqlString, values)
+					return err
+				})
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			total += count
+			rows = append(rows, shardRows...)
+			for column, typ := range shardTypes {
+				types[column] = typ
+			}
+		}(exec)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := resolveSortTypes(keys, types); err != nil {
+		return nil, err
+	}
+
+	sortRows(rows, keys)
+
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	rows = rows[offset:]
+	if b.limit > 0 && len(rows) > b.limit {
+		rows = rows[:b.limit]
+	}
+
+	return b.paginate(total, rows), nil
+}
+
+// queryRows read all rows as column maps, with the database type name of each column
+func queryRows(ctx context.Context, exec Executor, query string, values []interface{}) ([]Row, map[string]string, error) {
+	rows, err := exec.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var types = map[string]string{}
+	if columnTypes, err := rows.ColumnTypes(); err == nil {
+		for _, columnType := range columnTypes {
+			types[columnType.Name()] = strings.ToUpper(columnType.DatabaseTypeName())
+		}
+	}
+
+	result, err := scanRows(rows)
+	return result, types, err
+}
+
+// numericTypes column types scanned as strings which sort as numbers
+var numericTypes = map[string]bool{
+	"NUMERIC": true, "DECIMAL": true, "MONEY": true, "UNSIGNED DECIMAL": true,
+}
+
+// textTypes column types sorted by the collation of the database
+var textTypes = map[string]bool{
+	"TEXT": true, "VARCHAR": true, "CHAR": true, "BPCHAR": true, "CITEXT": true, "NAME": true,
+	"NVARCHAR": true, "NCHAR": true, "NTEXT": true, "TINYTEXT": true, "MEDIUMTEXT": true, "LONGTEXT": true,
+}
+
+// resolveSortTypes sort numeric keys as numbers and reject text keys the collation would order differently
+func resolveSortTypes(keys []sortKey, types map[string]string) error {
+	for i, key := range keys {
+		var typ = types[key.column]
+		switch {
+		case numericTypes[typ]:
+			keys[i].numeric = true
+		case textTypes[typ] && !key.bytewise:
+			return fmt.Errorf("%w: can't merge shards by text column %q, order it with COLLATE \"C\"", ErrInvalidOrder, key.column)
+		}
+	}
+
+	return nil
+}
+
+func scanRows(rows *sql.Rows) ([]Row, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = []Row{}
+	for rows.Next() {
+		row, err := scanRow(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// scanRow scan the current row by column name, []byte values are converted to strings
+func scanRow(rows *sql.Rows, columns []string) (Row, error) {
+	var values = make([]interface{}, len(columns))
+	var pointers = make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	var row = Row{}
+	for i, column := range columns {
+		if data, ok := values[i].([]byte); ok {
+			values[i] = string(data)
+		}
+		row[column] = values[i]
+	}
+
+	return row, nil
+}
+
+// parseSortKeys columns of an ORDER BY clause, ErrInvalidOrder for expressions
+func parseSortKeys(orderBy string) ([]sortKey, error) {
+	var keys = []sortKey{}
+	var nulls = map[string]bool{}
+	for _, part := range splitTopLevel(orderBy, ',') {
+		var fields = strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if column, first, ok := parseNullsCase(fields); ok {
+			nulls[column] = first
+			continue
+		}
+
+		var key, ok = parseSortKey(fields)
+		if !ok {
+			return nil, fmt.Errorf("%w: can't merge shards ordered by %q", ErrInvalidOrder, strings.TrimSpace(part))
+		}
+		if first, ok := nulls[fields[0]]; ok {
+			key.nullsFirst = first
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// parseSortKey parse col [ASC|DESC] [NULLS FIRST|LAST], NULLs are largest by default like postgres
+func parseSortKey(fields []string) (sortKey, bool) {
+	var column = fields[0]
+	if !isIdentifier(strings.NewReplacer(`"`, "", "`", "").Replace(column)) {
+		return sortKey{}, false
+	}
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+
+	var key = sortKey{column: strings.Trim(column, "\"`")}
+	fields = fields[1:]
+	if len(fields) > 1 && strings.EqualFold(fields[0], "COLLATE") {
+		// only the byte order collations merge like the database sorts
+		switch strings.ToUpper(strings.Trim(fields[1], "\"")) {
+		case "C", "POSIX":
+			key.bytewise = true
+		default:
+			return sortKey{}, false
+		}
+		fields = fields[2:]
+	}
+	if len(fields) > 0 && (strings.EqualFold(fields[0], "ASC") || strings.EqualFold(fields[0], "DESC")) {
+		key.desc = strings.EqualFold(fields[0], "DESC")
+		fields = fields[1:]
+	}
+	key.nullsFirst = key.desc
+	if len(fields) == 2 && strings.EqualFold(fields[0], "NULLS") {
+		switch strings.ToUpper(fields[1]) {
+		case "FIRST":
+			key.nullsFirst = true
+		case "LAST":
+			key.nullsFirst = false
+		default:
+			return sortKey{}, false
+		}
+		fields = fields[2:]
+	}
+
+	return key, len(fields) == 0
+}
+
+// parseNullsCase parse CASE WHEN col IS NULL THEN 0 ELSE 1 END, built by OrderByCol on MySQL
+func parseNullsCase(fields []string) (string, bool, bool) {
+	var pattern = []string{"CASE", "WHEN", "", "IS", "NULL", "THEN", "", "ELSE", "", "END"}
+	if len(fields) != len(pattern) {
+		return "", false, false
+	}
+	for i, word := range pattern {
+		if word != "" && !strings.EqualFold(fields[i], word) {
+			return "", false, false
+		}
+	}
+
+	switch fields[6] + fields[8] {
+	case "01":
+		return fields[2], true, true
+	case "10":
+		return fields[2], false, true
+	}
+	return "", false, false
+}
+
+// splitTopLevel split s on sep outside of parentheses
+func splitTopLevel(s string, sep byte) []string {
+	var parts = []string{}
+	var depth, start = 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// sortRows stable sort by keys, NULLs are placed by the nullsFirst of the key
+func sortRows(rows []Row, keys []sortKey) {
+	if len(keys) == 0 {
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range keys {
+			var x, y = rows[i][key.column], rows[j][key.column]
+			if x == nil || y == nil {
+				if x == nil && y == nil {
+					continue
+				}
+				return (x == nil) == key.nullsFirst
+			}
+
+			var c int
+			if key.numeric {
+				c = compareNumeric(x, y)
+			} else {
+				c = compareValues(x, y)
+			}
+			if c == 0 {
+				continue
+			}
+			if key.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compareValues compare two scanned values, nil is greater than everything
+func compareValues(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareNumeric compare NUMERIC values scanned as strings exactly, falling back to compareValues
+func compareNumeric(a interface{}, b interface{}) int {
+	x, okA := new(big.Rat).SetString(fmt.Sprint(a))
+	y, okB := new(big.Rat).SetString(fmt.Sprint(b))
+	if !okA || !okB {
+		return compareValues(a, b)
+	}
+
+	return x.Cmp(y)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSortRows(t *testing.T) {
+	var rows = []Row{
+		{"id": int64(1), "score": 10.5},
+		{"id": int64(2), "score": nil},
+		{"id": int64(3), "score": 30.0},
+		{"id": int64(4), "score": 10.5},
+	}
+
+	keys, err := parseSortKeys("t.score DESC, id ASC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortRows(rows, keys)
+
+	var ids = []int64{}
+	for _, row := range rows {
+		ids = append(ids, row["id"].(int64))
+	}
+	if ids[0] != 2 || ids[1] != 3 || ids[2] != 1 || ids[3] != 4 {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+}
+
+func TestSortRowsNulls(t *testing.T) {
+	var rows = []Row{
+		{"id": int64(1), "score": 10.5},
+		{"id": int64(2), "score": nil},
+		{"id": int64(3), "score": 30.0},
+	}
+
+	keys, err := parseSortKeys("score ASC NULLS FIRST")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortRows(rows, keys)
+	if rows[0]["id"] != int64(2) || rows[1]["id"] != int64(1) || rows[2]["id"] != int64(3) {
+		t.Fatalf("unexpected order: %v", rows)
+	}
+
+	keys, err = parseSortKeys("score DESC NULLS LAST")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortRows(rows, keys)
+	if rows[0]["id"] != int64(3) || rows[1]["id"] != int64(1) || rows[2]["id"] != int64(2) {
+		t.Fatalf("unexpected order: %v", rows)
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := parseSortKeys(`u."created_at" DESC, id, score NULLS FIRST`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 3 || keys[0].column != "created_at" || !keys[0].desc || !keys[0].nullsFirst ||
+		keys[1].column != "id" || keys[1].desc || keys[1].nullsFirst || !keys[2].nullsFirst {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	keys, err = parseSortKeys("CASE WHEN score IS NULL THEN 1 ELSE 0 END,score DESC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].column != "score" || keys[0].nullsFirst {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	for _, orderBy := range []string{
+		"COALESCE(a, b)",
+		"CASE WHEN status = 'new' THEN 0 ELSE 1 END",
+		"LOWER(name) ASC",
+		"name COLLATE \"de-DE-x-icu\"",
+		"1 DESC",
+		"score DESC NULLS",
+	} {
+		if _, err := parseSortKeys(orderBy); !errors.Is(err, ErrInvalidOrder) {
+			t.Fatalf("expected ErrInvalidOrder for %q, got %v", orderBy, err)
+		}
+	}
+}
+
+func TestSortRowsTypes(t *testing.T) {
+	var rows = []Row{
+		{"id": int64(1), "amount": "10"},
+		{"id": int64(2), "amount": "9"},
+		{"id": int64(3), "amount": "9.50"},
+	}
+
+	keys, err := parseSortKeys("amount")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := resolveSortTypes(keys, map[string]string{"amount": "NUMERIC"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortRows(rows, keys)
+	if rows[0]["id"] != int64(2) || rows[1]["id"] != int64(3) || rows[2]["id"] != int64(1) {
+		t.Fatalf("unexpected order: %v", rows)
+	}
+
+	keys, _ = parseSortKeys("name DESC")
+	if err := resolveSortTypes(keys, map[string]string{"name": "VARCHAR"}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder for a text key, got %v", err)
+	}
+
+	keys, err = parseSortKeys(`name COLLATE "C" DESC`)
+	if err != nil || !keys[0].bytewise || !keys[0].desc {
+		t.Fatalf("unexpected keys %+v: %v", keys, err)
+	}
+	if err := resolveSortTypes(keys, map[string]string{"name": "VARCHAR"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
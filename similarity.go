@@ -0,0 +1,42 @@
+package query
+
+import (
+	"fmt"
+)
+
+// defaultSimilarityThreshold pg_trgm.similarity_threshold default, used by the indexable % operator
+const defaultSimilarityThreshold = 0.3
+
+type similarity struct {
+	column string
+	term   string
+}
+
+// WhereSimilar filter rows whose column is similar to term with pg_trgm, 0 uses the server threshold
+func (b *Builder) WhereSimilar(column string, term string, threshold float64) *Builder {
+	b.similar = &similarity{
+		column: column,
+		term:   term,
+	}
+
+	if threshold <= 0 {
+		return b.Where(fmt.Sprintf("%s %% ?", column), term)
+	}
+
+	if threshold >= defaultSimilarityThreshold {
+		// keep the % operator so trigram indexes can be used, then apply the stricter threshold
+		return b.Where(fmt.Sprintf("%s %% ? AND similarity(%s, ?) >= ?", column, column), term, term, threshold)
+	}
+
+	return b.Where(fmt.Sprintf("similarity(%s, ?) >= ?", column), term, threshold)
+}
+
+// WithSimilarityRank select the similarity of the last WhereSimilar as alias and order by it, closest first
+func (b *Builder) WithSimilarityRank(alias string) *Builder {
+	if b.similar == nil {
+		return b
+	}
+
+	b.rankOrderBy = append(b.rankOrderBy, fmt.Sprintf("%s DESC", alias))
+	return b.AddSelect(fmt.Sprintf("similarity(%s, ?) AS %s", b.similar.column, alias), b.similar.term)
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownSort returned when ordering by a sort name which isn't registered
+var ErrUnknownSort = errors.New("query: unknown sort")
+
+type namedSort struct {
+	expr string
+	args []interface{}
+}
+
+var sorts sync.Map
+
+// RegisterSort register a sort expression clients request by name with OrderBySort
+func RegisterSort(name string, expr string, args ...interface{}) {
+	sorts.Store(name, namedSort{
+		expr: expr,
+		args: args,
+	})
+}
+
+// OrderBySort append the registered sort to the ORDER BY, after OrderBy
+func (b *Builder) OrderBySort(name string, args ...interface{}) *Builder {
+	value, ok := sorts.Load(name)
+	if !ok {
+		b.fail(fmt.Errorf("%w: %q", ErrUnknownSort, name))
+		return b
+	}
+
+	var sort = value.(namedSort)
+	b.addSort(sortTerm{expr: sort.expr}, append(sort.args[:len(sort.args):len(sort.args)], args...)...)
+	return b
+}
+
+// sortTerm ORDER BY term, rendered for the dialect at build time
+type sortTerm struct {
+	expr    string
+	dir     Direction
+	nulls   NullsOrder
+	collate string
+	lower   bool
+	random  bool
+}
+
+// addSort append an ORDER BY term and its bound values
+func (b *Builder) addSort(term sortTerm, args ...interface{}) {
+	b.sorts = append(b.sorts, term)
+	b.sortValues = append(b.sortValues, args...)
+}
+
+// fail record the first builder error, returned on execution
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,35 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderBySort(t *testing.T) {
+	RegisterSort("relevance", "ts_rank(search_vector, plainto_tsquery(?)) DESC")
+	RegisterSort("newest", "created_at DESC")
+
+	var b = New(nil, "SELECT * FROM articles").
+		Where("status = ?", "published").
+		OrderBy("pinned DESC").
+		OrderBySort("relevance", "golang").
+		OrderBySort("newest").
+		Limit(10)
+
+	queryString, _ := b.build()
+	if queryString != "SELECT * FROM articles WHERE status = ? ORDER BY pinned DESC,ts_rank(search_vector, plainto_tsquery(?)) DESC,created_at DESC LIMIT 10" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if values := b.values(); !reflect.DeepEqual(values, []interface{}{"published", "golang"}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	b = New(nil, "SELECT * FROM articles").OrderBySort("id; DROP TABLE articles")
+	if queryString, _ = b.build(); queryString != "SELECT * FROM articles" {
+		t.Fatalf("unknown sort reached the SQL: %s", queryString)
+	}
+	if err := b.validate(); !errors.Is(err, ErrUnknownSort) {
+		t.Fatalf("expected ErrUnknownSort, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLXConn sqlx handle, satisfied by *sqlx.DB and *sqlx.Tx
+type SQLXConn interface {
+	SQLConn
+	DriverName() string
+	Rebind(query string) string
+}
+
+// SQLXExecutor executor on a sqlx handle, binding sql.NamedArg arguments with sqlx.Named
+type SQLXExecutor struct {
+	conn SQLXConn
+}
+
+// NewSQLXExecutor init an executor on conn
+func NewSQLXExecutor(conn SQLXConn) *SQLXExecutor {
+	return &SQLXExecutor{
+		conn: conn,
+	}
+}
+
+// QueryContext ...
+func (e *SQLXExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args, err := e.bind(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return e.conn.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext ...
+func (e *SQLXExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, boundArgs, err := e.bind(query, args)
+	if err != nil {
+		// let the driver report the unbound statement, *sql.Row can't carry our error
+		return e.conn.QueryRowContext(ctx, query, args...)
+	}
+	return e.conn.QueryRowContext(ctx, query, boundArgs...)
+}
+
+// ExecContext ...
+func (e *SQLXExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args, err := e.bind(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return e.conn.ExecContext(ctx, query, args...)
+}
+
+// Dialect ...
+func (e *SQLXExecutor) Dialect() string {
+	switch e.conn.DriverName() {
+	case "postgres", "pgx", "cloudsqlpostgres", "ql", "nrpostgres", "cockroach":
+		return DialectPostgres
+	case "mysql", "nrmysql":
+		return DialectMySQL
+	case "sqlite3", "sqlite", "nrsqlite3":
+		return DialectSQLite
+	case "sqlserver", "mssql":
+		return DialectSQLServer
+	default:
+		return e.conn.DriverName()
+	}
+}
+
+// bind named arguments, expand slices and rebind to the driver bindvars
+func (e *SQLXExecutor) bind(query string, args []interface{}) (string, []interface{}, error) {
+	query, args = expandArgs(query, args, false)
+
+	if named, ok := namedArgs(args); ok {
+		var err error
+		if query, args, err = sqlx.Named(query, named); err != nil {
+			return "", nil, err
+		}
+	}
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return e.conn.Rebind(query), args, nil
+}
+
+// namedArgs collect the arguments into a map when all of them are sql.NamedArg
+func namedArgs(args []interface{}) (map[string]interface{}, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+
+	var named = map[string]interface{}{}
+	for _, arg := range args {
+		namedArg, ok := arg.(sql.NamedArg)
+		if !ok {
+			return nil, false
+		}
+		named[namedArg.Name] = namedArg.Value
+	}
+
+	return named, true
+}
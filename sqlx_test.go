@@ -0,0 +1,33 @@
+package query
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSQLXBind(t *testing.T) {
+	var conn = sqlx.NewDb(&sql.DB{}, "pgx")
+	var exec = NewSQLXExecutor(conn)
+
+	query, args, err := exec.bind("SELECT * FROM users WHERE id IN (?) AND kind = ?", []interface{}{[]int{1, 2}, "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users WHERE id IN ($1, $2) AND kind = $3" || len(args) != 3 {
+		t.Fatalf("unexpected bind: %s %v", query, args)
+	}
+
+	query, args, err = exec.bind("SELECT * FROM users WHERE email = :email", []interface{}{sql.Named("email", "a@test.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users WHERE email = $1" || len(args) != 1 || args[0] != "a@test.com" {
+		t.Fatalf("unexpected bind: %s %v", query, args)
+	}
+
+	if exec.Dialect() != DialectPostgres {
+		t.Fatalf("unexpected dialect: %s", exec.Dialect())
+	}
+}
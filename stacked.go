@@ -0,0 +1,76 @@
+package query
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrStackedStatements returned when the built SQL holds more than one statement
+var ErrStackedStatements = errors.New("query: multiple statements are not allowed")
+
+// checkSingleStatement reject semicolons outside literals and comments, except a trailing one
+func checkSingleStatement(sql string) error {
+	if strings.IndexByte(sql, ';') < 0 {
+		return nil
+	}
+
+	for _, backslash := range []bool{false, true} {
+		if at := statementEnd(sql, backslash); at >= 0 && strings.TrimSpace(sql[at+1:]) != "" {
+			return ErrStackedStatements
+		}
+	}
+
+	return nil
+}
+
+// statementEnd index of the first semicolon outside of literals and comments, -1 if none
+func statementEnd(sql string, backslash bool) int {
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == ';':
+			return i
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(sql) && sql[i] != c; i++ {
+				if backslash && sql[i] == '\\' {
+					i++
+				}
+			}
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '$':
+			// postgres dollar quoting, $$...$$ or $tag$...$tag$
+			var tagEnd = strings.IndexByte(sql[i+1:], '$')
+			if tagEnd < 0 || !isDollarTag(sql[i+1:i+1+tagEnd]) {
+				continue
+			}
+			var tag = sql[i : i+tagEnd+2]
+			if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag) - 1
+			} else {
+				i = len(sql)
+			}
+		}
+	}
+
+	return -1
+}
+
+func isDollarTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		var c = tag[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckSingleStatement(t *testing.T) {
+	var tests = []struct {
+		sql     string
+		stacked bool
+	}{
+		{"SELECT * FROM users WHERE name = 'a;b'", false},
+		{"SELECT * FROM users;", false},
+		{"SELECT * FROM users -- ; comment\nWHERE id = 1", false},
+		{"SELECT * FROM users /* ; */ WHERE id = 1", false},
+		{"SELECT $$;$$, $fn$ a; b $fn$ FROM users", false},
+		{"SELECT * FROM users WHERE id = $1", false},
+		{"SELECT * FROM users; DROP TABLE users", true},
+		{"SELECT * FROM users WHERE name = 'x\\'; DROP TABLE users; --'", true},
+	}
+
+	for _, test := range tests {
+		var err = checkSingleStatement(test.sql)
+		if (err == ErrStackedStatements) != test.stacked {
+			t.Errorf("%s: got %v", test.sql, err)
+		}
+	}
+
+	var b = New(nil, "SELECT * FROM users WHERE name = @name").WhereNamed("name", "x'; DELETE FROM users; --")
+	sqlString, _ := b.build()
+	if err := checkSingleStatement(sqlString); err != ErrStackedStatements {
+		t.Fatalf("named value should be rejected: %s", sqlString)
+	}
+	if err := New(nil, "SELECT * FROM users; DROP TABLE users").IntoTemp(context.Background(), nil, "snapshot"); err != ErrStackedStatements {
+		t.Fatalf("IntoTemp should reject stacked statements, got %v", err)
+	}
+	if _, err := New(nil, "SELECT * FROM users; DROP TABLE users").PagingShards(context.Background()); err != ErrStackedStatements {
+		t.Fatalf("PagingShards should reject stacked statements, got %v", err)
+	}
+}
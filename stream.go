@@ -0,0 +1,104 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RowStreamFunc receive in order the rows of a page too large to collect
+type RowStreamFunc = func(row Row) error
+
+// streamedPage records of a page passed to the stream func
+type streamedPage struct{}
+
+// WithStreamBudget stream the pages over maxRows rows or maxBytes, zero disables the limit
+func (b *Builder) WithStreamBudget(maxRows int, maxBytes int) *Builder {
+	b.streamRows = maxRows
+	b.streamBytes = maxBytes
+	return b
+}
+
+// PagingStream paging with []Row records, streaming the pages over the budget
+func (b *Builder) PagingStream(ctx context.Context, stream RowStreamFunc) (*Pagination, error) {
+	// a cached or shared page would never reach stream
+	var uncached = *b
+	uncached.cache = nil
+	uncached.flight = ""
+
+	pagination, err := uncached.PagingRows(ctx, func(rows *sql.Rows) (interface{}, error) {
+		return b.collectRows(rows, stream)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := pagination.Records.(streamedPage); ok {
+		pagination.Records = nil
+		pagination.Streamed = true
+	}
+
+	return pagination, nil
+}
+
+// collectRows collect the rows within the budget, then switch to stream
+func (b *Builder) collectRows(rows *sql.Rows, stream RowStreamFunc) (interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = []Row{}
+	var size = 0
+	var streaming = false
+	for rows.Next() {
+		row, err := scanRow(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+
+		if streaming {
+			if err = stream(row); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result = append(result, row)
+		size += rowSize(row)
+		if (b.streamRows > 0 && len(result) > b.streamRows) || (b.streamBytes > 0 && size > b.streamBytes) {
+			streaming = true
+			for _, collected := range result {
+				if err = stream(collected); err != nil {
+					return nil, err
+				}
+			}
+			result = nil
+		}
+	}
+
+	if streaming {
+		return streamedPage{}, nil
+	}
+	return result, nil
+}
+
+// rowSize estimated memory of the row values
+func rowSize(row Row) int {
+	var size = 0
+	for column, value := range row {
+		size += len(column)
+		switch v := value.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case time.Time:
+			size += 24
+		default:
+			size += 8
+		}
+	}
+
+	return size
+}
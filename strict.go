@@ -0,0 +1,17 @@
+package query
+
+// Strict zero the missing NextPage and PrevPage and fail unordered pagination
+func (b *Builder) Strict() *Builder {
+	b.strict = true
+	return b
+}
+
+// strictPages clear the pages that don't exist
+func (b *Builder) strictPages(pagination *Pagination) {
+	if !pagination.HasNext {
+		pagination.NextPage = 0
+	}
+	if !pagination.HasPrev {
+		pagination.PrevPage = 0
+	}
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"database/sql"
+)
+
+// WithSummary attach aggregates over every filtered row to Pagination.Summary
+func (b *Builder) WithSummary(aggregates string) *Builder {
+	b.summary = aggregates
+	return b
+}
+
+// summaryCountSQL count query computing the summary aggregates, countSQLString without summary
+func (b *Builder) summaryCountSQL(countSQLString string) string {
+	if b.summary == "" {
+		return countSQLString
+	}
+
+	with, _ := b.buildWith()
+	return b.hintComment() + wrapQuery(with, b.buildBody(), "COUNT(1), "+b.summary)
+}
+
+// scanSummary scan the count and the summary aggregates of the single row of the count query
+func scanSummary(rows *sql.Rows, err error) (int, map[string]interface{}, error) {
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	return scanSummaryRow(rows)
+}
+
+// scanSummaryRow scan the next row of the count query, the summary is nil without aggregates
+func scanSummaryRow(rows *sql.Rows) (int, map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var count int
+	var values = make([]interface{}, len(columns))
+	var dest = make([]interface{}, len(columns))
+	dest[0] = &count
+	for i := 1; i < len(columns); i++ {
+		dest[i] = &values[i]
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, nil, err
+		}
+		return 0, nil, sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, nil, err
+	}
+
+	if len(columns) == 1 {
+		return count, nil, nil
+	}
+
+	var summary = make(map[string]interface{}, len(columns)-1)
+	for i := 1; i < len(columns); i++ {
+		if raw, ok := values[i].([]byte); ok {
+			values[i] = string(raw)
+		}
+		summary[columns[i]] = values[i]
+	}
+
+	return count, summary, nil
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsafeUserInput returned when a value marked with UserInput is formatted into the SQL instead of bound
+var ErrUnsafeUserInput = errors.New("query: user input formatted into SQL")
+
+type userInput struct {
+	value interface{}
+}
+
+// UserInput mark a user-provided value, which must be bound instead of formatted
+func UserInput(value interface{}) interface{} {
+	return userInput{value}
+}
+
+// String keep the marker out of formatted SQL
+func (u userInput) String() string {
+	return fmt.Sprint(u.value)
+}
+
+// taint record a user input formatted into the SQL
+func (b *Builder) taint(value interface{}, where string) {
+	if _, ok := value.(userInput); ok {
+		b.tainted = append(b.tainted, where)
+	}
+}
+
+// checkTaint reject builders with user input formatted into the SQL
+func (b *Builder) checkTaint() error {
+	if len(b.tainted) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsafeUserInput, b.tainted[0])
+	}
+	return nil
+}
+
+// unwrapUserInput replace the marked values by the values themselves
+func unwrapUserInput(values []interface{}) {
+	for i, value := range values {
+		if u, ok := value.(userInput); ok {
+			values[i] = u.value
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUserInput(t *testing.T) {
+	var b = New(nil, "SELECT * FROM users").Where("name = ?", UserInput("john"))
+	if err := b.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if values := b.values(); !reflect.DeepEqual(values, []interface{}{"john"}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	b = New(nil, "SELECT * FROM users WHERE name = @name").WhereNamed("name", UserInput("john"))
+	if err := b.validate(); !errors.Is(err, ErrUnsafeUserInput) {
+		t.Fatalf("expected ErrUnsafeUserInput, got %v", err)
+	}
+
+	b = New(nil, "SELECT * FROM users").Where(UserInput("1 = 1"))
+	if err := b.validate(); !errors.Is(err, ErrUnsafeUserInput) {
+		t.Fatalf("expected ErrUnsafeUserInput, got %v", err)
+	}
+}
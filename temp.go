@@ -0,0 +1,75 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTable returned when a temporary table name isn't an identifier
+var ErrInvalidTable = errors.New("query: invalid table name")
+
+// IntoTemp create a temporary table from the built query on tx, temp tables live on one connection
+func (b *Builder) IntoTemp(ctx context.Context, tx Executor, name string) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidTable, name)
+	}
+
+	sqlString, _ := b.build()
+	var values = b.values()
+
+	if err := b.beforeExec(ctx, statement{sqlString, values}); err != nil {
+		return err
+	}
+
+	var stmt = fmt.Sprintf("CREATE TEMPORARY TABLE %s AS %s", name, sqlString)
+	if err := checkSingleStatement(stmt); err != nil {
+		return err
+	}
+
+	if tx == nil {
+		return ErrNilDB
+	}
+	if b.readOnlyTx {
+		return ErrReadOnlySession
+	}
+
+	schema, err := b.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+	if schema != "" {
+		if tx.Dialect() != DialectPostgres {
+			return ErrSchemaNotSupported
+		}
+		if err := setSearchPath(ctx, tx, schema); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, stmt, values...)
+	return err
+}
+
+// DropTemp drop a temporary table created by IntoTemp on tx
+func (b *Builder) DropTemp(ctx context.Context, tx Executor, name string) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidTable, name)
+	}
+
+	var stmt = fmt.Sprintf("DROP TABLE IF EXISTS %s", name)
+	if tx.Dialect() == DialectMySQL {
+		stmt = fmt.Sprintf("DROP TEMPORARY TABLE IF EXISTS %s", name)
+	}
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+// Chain start a follow-up query on tx, e.g. joining a temporary table created by IntoTemp
+func (b *Builder) Chain(tx Executor, rawSQL string) *Builder {
+	var chained = New(b.db, rawSQL)
+	chained.exec = tx
+	chained.dialectName = b.dialectName
+	return chained
+}
@@ -0,0 +1,136 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"gorm.io/gorm"
+)
+
+// ErrSchemaNotSupported returned when the schema can't be switched on the dialect or executor
+var ErrSchemaNotSupported = errors.New("query: schema switching is not supported")
+
+// ErrSchemaSession returned by Rows when a schema is set, open rows can't keep the schema session
+var ErrSchemaSession = errors.New("query: schema requires a session, use PagingRows, Count or ScanRow")
+
+// SchemaResolver resolve the tenant schema of a request, empty for the default search_path
+type SchemaResolver = func(ctx context.Context) (string, error)
+
+// Transactor executor able to run a dedicated session
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(tx Executor) error) error
+}
+
+type schemaKey struct{}
+
+var schemaResolver SchemaResolver = func(ctx context.Context) (string, error) {
+	schema, _ := ctx.Value(schemaKey{}).(string)
+	return schema, nil
+}
+
+// ContextWithSchema attach the tenant schema to ctx, picked up by builders without WithSchema
+func ContextWithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaKey{}, schema)
+}
+
+// SetSchemaResolver replace the resolver reading ContextWithSchema
+func SetSchemaResolver(resolver SchemaResolver) {
+	schemaResolver = resolver
+}
+
+// WithSchema run the queries with search_path set to schema, in a dedicated transaction
+func (b *Builder) WithSchema(schema string) *Builder {
+	b.schema = schema
+	return b
+}
+
+// resolveSchema schema of the builder or of the request
+func (b *Builder) resolveSchema(ctx context.Context) (string, error) {
+	if b.schema != "" {
+		return b.schema, nil
+	}
+
+	return schemaResolver(ctx)
+}
+
+// session run fn on the reader, in a transaction for schemas and ReadOnlyTx, dedicated on one connection
+func (b *Builder) session(ctx context.Context, fn func(exec Executor, dedicated bool) error) error {
+	schema, err := b.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	var exec = b.reader(ctx)
+	if schema == "" && !b.readOnlyTx {
+		return fn(exec, false)
+	}
+
+	transactor, ok := exec.(Transactor)
+	if !ok || exec.Dialect() != DialectPostgres {
+		if schema == "" {
+			return ErrReadOnlyNotSupported
+		}
+		return ErrSchemaNotSupported
+	}
+
+	return transactor.Transaction(ctx, func(tx Executor) error {
+		// must be the first statement of the transaction
+		if b.readOnlyTx {
+			if _, err := tx.ExecContext(ctx, "SET TRANSACTION READ ONLY"); err != nil {
+				return err
+			}
+		}
+		if schema == "" {
+			return fn(tx, true)
+		}
+
+		if err := setSearchPath(ctx, tx, schema); err != nil {
+			return err
+		}
+
+		return fn(tx, true)
+	})
+}
+
+// setSearchPath set the search_path of the transaction tx to schema
+func setSearchPath(ctx context.Context, tx Executor, schema string) error {
+	// is_local resets the search_path at the end of the transaction, so pooled connections don't leak it
+	_, err := tx.ExecContext(ctx, "SELECT set_config('search_path', ?, true)", pgx.Identifier{schema}.Sanitize())
+	return err
+}
+
+// gormSession run fn on the gorm DB, in a transaction for schemas and ReadOnlyTx, dedicated on one connection
+func (b *Builder) gormSession(ctx context.Context, fn func(db DB, dedicated bool) error) error {
+	schema, err := b.resolveSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	if schema == "" && !b.readOnlyTx {
+		return fn(b.db, false)
+	}
+
+	if NewGormExecutor(b.db).Dialect() != DialectPostgres {
+		if schema == "" {
+			return ErrReadOnlyNotSupported
+		}
+		return ErrSchemaNotSupported
+	}
+
+	return b.db.GetGorm().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// must be the first statement of the transaction
+		if b.readOnlyTx {
+			if err := tx.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+				return err
+			}
+		}
+		if schema != "" {
+			if err := tx.Exec("SELECT set_config('search_path', ?, true)", pgx.Identifier{schema}.Sanitize()).Error; err != nil {
+				return err
+			}
+		}
+
+		return fn(WrapGorm(tx), true)
+	})
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Values build a (VALUES (?, ?)) AS v(col1, col2) fragment with bound parameters
+func Values(rows [][]interface{}, columns ...string) clause.Expr {
+	var alias = "v"
+	if len(columns) > 0 {
+		alias = fmt.Sprintf("v(%s)", strings.Join(columns, ", "))
+	}
+
+	if len(rows) == 0 {
+		// VALUES requires at least one row, select nothing instead
+		var width = len(columns)
+		if width == 0 {
+			width = 1
+		}
+		var nulls = strings.TrimSuffix(strings.Repeat("NULL, ", width), ", ")
+		return clause.Expr{SQL: fmt.Sprintf("(SELECT %s WHERE FALSE) AS %s", nulls, alias)}
+	}
+
+	var tuples = make([]string, 0, len(rows))
+	var vars = []interface{}{}
+	for _, row := range rows {
+		var placeholders = strings.TrimSuffix(strings.Repeat("?, ", len(row)), ", ")
+		tuples = append(tuples, fmt.Sprintf("(%s)", placeholders))
+		vars = append(vars, row...)
+	}
+
+	return clause.Expr{
+		SQL:  fmt.Sprintf("(VALUES %s) AS %s", strings.Join(tuples, ", "), alias),
+		Vars: vars,
+	}
+}
@@ -0,0 +1,35 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	var expr = Values([][]interface{}{{1, "a"}, {2, "b"}}, "id", "kind")
+	if expr.SQL != "(VALUES (?, ?), (?, ?)) AS v(id, kind)" {
+		t.Fatalf("unexpected sql: %s", expr.SQL)
+	}
+	if len(expr.Vars) != 4 {
+		t.Fatalf("unexpected vars: %v", expr.Vars)
+	}
+
+	expr = Values(nil, "id", "kind")
+	if expr.SQL != "(SELECT NULL, NULL WHERE FALSE) AS v(id, kind)" {
+		t.Fatalf("unexpected sql: %s", expr.SQL)
+	}
+}
+
+func TestJoinsBeforeWhere(t *testing.T) {
+	var b = New(nil, "SELECT u.* FROM users u").
+		Where("u.deleted_at IS NULL").
+		Joins("JOIN ? ON v.id = u.id", Values([][]interface{}{{1}, {2}}, "id")).
+		Where("u.email LIKE ?", "%@test.com")
+
+	queryString, _ := b.build()
+	if queryString != "SELECT u.* FROM users u JOIN ? ON v.id = u.id WHERE u.deleted_at IS NULL AND u.email LIKE ?" {
+		t.Fatalf("unexpected query: %s", queryString)
+	}
+	if values := b.values(); len(values) != 2 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNoListener returned when watching a builder without WithListener
+var ErrNoListener = errors.New("query: Watch needs WithListener")
+
+// WithListener listener whose notifications Watch re-executes the builder on
+func (b *Builder) WithListener(listener *Listener) *Builder {
+	b.listener = listener
+	return b
+}
+
+// watcher invalidator signalling its Watch, notifications fired while a page runs coalesce
+type watcher struct {
+	fired chan struct{}
+}
+
+func (w *watcher) Invalidate(channel string, payload string) {
+	select {
+	case w.fired <- struct{}{}:
+	default:
+	}
+}
+
+// Watch send the page now and on each notification, failures carry Err, cached builders need a CacheInvalidator
+func (b *Builder) Watch(ctx context.Context, channel string) (<-chan *Pagination, error) {
+	if b.listener == nil {
+		return nil, ErrNoListener
+	}
+
+	var page = func() (*Pagination, error) {
+		return b.PagingRows(ctx, func(rows *sql.Rows) (interface{}, error) {
+			return scanRows(rows)
+		})
+	}
+
+	// subscribe first so changes made while the first page runs fire again
+	var w = &watcher{fired: make(chan struct{}, 1)}
+	b.listener.Subscribe(channel, w)
+
+	pagination, err := page()
+	if err != nil {
+		b.listener.unsubscribe(channel, w)
+		return nil, err
+	}
+
+	var pages = make(chan *Pagination, 1)
+	pages <- pagination
+	go func() {
+		defer close(pages)
+		defer b.listener.unsubscribe(channel, w)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.fired:
+			}
+
+			pagination, err := page()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				pagination = &Pagination{Err: err}
+			}
+
+			select {
+			case pages <- pagination:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return pages, nil
+}
@@ -0,0 +1,220 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrNoTimeColumn returned when paginating by time without WithTimeColumn
+var ErrNoTimeColumn = errors.New("query: Since and Until need WithTimeColumn")
+
+// TimeWindow boundary timestamps and ids of the rows of a time paginated page
+type TimeWindow struct {
+	Oldest   time.Time   `json:"oldest"`
+	OldestID interface{} `json:"oldest_id"`
+	Newest   time.Time   `json:"newest"`
+	NewestID interface{} `json:"newest_id"`
+}
+
+type timeWindow struct {
+	column string
+	id     string
+	since  bool
+	until  bool
+}
+
+// WithTimeColumn paginate by the timestamp column, ties broken by idColumn, see Since and Until
+func (b *Builder) WithTimeColumn(column string, idColumn string) *Builder {
+	for _, c := range []string{column, idColumn} {
+		if !isIdentifier(c) {
+			b.fail(fmt.Errorf("%w: %q", ErrInvalidColumn, c))
+			return b
+		}
+	}
+
+	b.window = &timeWindow{column: column, id: idColumn}
+	return b
+}
+
+// Since rows after the row id at t oldest first, a nil id starts after t, see WithTimeColumn
+func (b *Builder) Since(t time.Time, id interface{}) *Builder {
+	if b.window == nil {
+		b.fail(ErrNoTimeColumn)
+		return b
+	}
+
+	b.window.since = true
+	return b.window.where(b, ">", t, id)
+}
+
+// Until rows before the row id at t newest first, a nil id starts before t, see WithTimeColumn
+func (b *Builder) Until(t time.Time, id interface{}) *Builder {
+	if b.window == nil {
+		b.fail(ErrNoTimeColumn)
+		return b
+	}
+
+	b.window.until = true
+	return b.window.where(b, "<", t, id)
+}
+
+// where compare the rows with the boundary row, or its timestamp alone without id
+func (w *timeWindow) where(b *Builder, op string, t time.Time, id interface{}) *Builder {
+	if id == nil {
+		return b.Where(w.column+" "+op+" ?", t)
+	}
+	return b.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", w.column, w.id, op), t, id)
+}
+
+// order newest first, unless only reading forward from Since
+func (w *timeWindow) order() string {
+	if w.since && !w.until {
+		return w.column + " ASC," + w.id + " ASC"
+	}
+	return w.column + " DESC," + w.id + " DESC"
+}
+
+// attachWindow attach the boundary timestamps and ids of the fetched records to the pagination
+func (b *Builder) attachWindow(pagination *Pagination) {
+	if b.window == nil || pagination == nil {
+		return
+	}
+
+	// the records hold the columns unqualified
+	var column = b.window.column[strings.LastIndexByte(b.window.column, '.')+1:]
+	var idColumn = b.window.id[strings.LastIndexByte(b.window.id, '.')+1:]
+	var records = reflect.ValueOf(pagination.Records)
+	for records.Kind() == reflect.Ptr || records.Kind() == reflect.Interface {
+		records = records.Elem()
+	}
+	if records.Kind() != reflect.Slice && records.Kind() != reflect.Array {
+		return
+	}
+
+	var window *TimeWindow
+	for i := 0; i < records.Len(); i++ {
+		value, ok := recordValue(records.Index(i), column)
+		if !ok {
+			continue
+		}
+		t, ok := timeValue(value)
+		if !ok {
+			continue
+		}
+		var id interface{}
+		if value, ok := recordValue(records.Index(i), idColumn); ok {
+			id = plainValue(value)
+		}
+
+		if window == nil {
+			window = &TimeWindow{Oldest: t, OldestID: id, Newest: t, NewestID: id}
+		}
+		if t.Before(window.Oldest) || t.Equal(window.Oldest) && compareValues(id, window.OldestID) < 0 {
+			window.Oldest, window.OldestID = t, id
+		}
+		if t.After(window.Newest) || t.Equal(window.Newest) && compareValues(id, window.NewestID) > 0 {
+			window.Newest, window.NewestID = t, id
+		}
+	}
+	pagination.Window = window
+}
+
+// recordValue value of column in a Row or a struct, matched by json, gorm or db tag or snake_case field name
+func recordValue(record reflect.Value, column string) (reflect.Value, bool) {
+	for record.Kind() == reflect.Ptr || record.Kind() == reflect.Interface {
+		if record.IsNil() {
+			return reflect.Value{}, false
+		}
+		record = record.Elem()
+	}
+
+	switch record.Kind() {
+	case reflect.Map:
+		if record.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		var value = record.MapIndex(reflect.ValueOf(column).Convert(record.Type().Key()))
+		return value, value.IsValid()
+	case reflect.Struct:
+		for i := 0; i < record.NumField(); i++ {
+			var field = record.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Anonymous {
+				if value, ok := recordValue(record.Field(i), column); ok {
+					return value, true
+				}
+				continue
+			}
+			if fieldColumn(field) == column {
+				return record.Field(i), true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// plainValue interface of a value without its pointers, nil for a nil pointer
+func plainValue(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	return value.Interface()
+}
+
+// fieldColumn column name of a struct field
+func fieldColumn(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	for _, option := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if strings.HasPrefix(option, "column:") {
+			return strings.TrimPrefix(option, "column:")
+		}
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("db"), ","); name != "" && name != "-" {
+		return name
+	}
+
+	// acronyms stay one word, e.g. UserID is user_id
+	var name strings.Builder
+	var runes = []rune(field.Name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// timeValue time.Time, *time.Time or sql.NullTime value
+func timeValue(value reflect.Value) (time.Time, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return time.Time{}, false
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v, true
+	case sql.NullTime:
+		return v.Time, v.Valid
+	}
+	return time.Time{}, false
+}